@@ -9,6 +9,8 @@ import (
 	"github.com/example/vkube-topology/backend/internal/api"
 	"github.com/example/vkube-topology/backend/internal/config"
 	"github.com/example/vkube-topology/backend/internal/db"
+	"github.com/example/vkube-topology/backend/internal/k8s"
+	"github.com/example/vkube-topology/backend/internal/snapshot"
 )
 
 func main() {
@@ -31,10 +33,31 @@ func main() {
 		log.Fatalf("erro ao migrar modelos: %v", err)
 	}
 
+	// Regras de arestas declaradas (dependências que não aparecem nos objetos)
+	if cfg.EdgeRulesFile != "" {
+		rules, err := k8s.LoadEdgeRules(cfg.EdgeRulesFile)
+		if err != nil {
+			log.Fatalf("erro ao carregar regras de arestas: %v", err)
+		}
+		if err := k8s.SetEdgeRules(rules); err != nil {
+			log.Fatalf("erro ao carregar regras de arestas: %v", err)
+		}
+		log.Printf("%d regra(s) de arestas carregada(s) de %s", len(rules), cfg.EdgeRulesFile)
+	}
+
+	// Cache de informers por cluster (iniciado sob demanda)
+	clusters := k8s.NewClusterManager(cfg.CacheIdleTTL)
+	defer clusters.Stop()
+
+	// Snapshots periódicos da topologia (a cada PollInterval)
+	snapshots := snapshot.NewService(cfg, clusters)
+	snapshots.Start()
+	defer snapshots.Stop()
+
 	r := gin.Default()
 
 	// Registra rotas da API
-	api.RegisterRoutes(r, cfg)
+	api.RegisterRoutes(r, cfg, clusters, snapshots)
 
 	port := cfg.AppPort
 	if port == "" {
@@ -0,0 +1,163 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+
+	"github.com/example/vkube-topology/backend/internal/config"
+	"github.com/example/vkube-topology/backend/internal/crypto"
+	"github.com/example/vkube-topology/backend/internal/db"
+	"github.com/example/vkube-topology/backend/internal/k8s"
+	"github.com/example/vkube-topology/backend/internal/models"
+	"github.com/example/vkube-topology/backend/internal/snapshot"
+)
+
+// =================================================================================
+// TOPOLOGY DIFF
+// =================================================================================
+
+// diffSide descreve de onde veio cada lado do diff.
+type diffSide struct {
+	Source     string     `json:"source"` // "live" ou "snapshot"
+	SnapshotID uint       `json:"snapshotId,omitempty"`
+	Time       *time.Time `json:"time,omitempty"`
+	Namespace  string     `json:"namespace,omitempty"`
+}
+
+// topologyDiffHandler compara duas topologias do cluster.
+//
+//	from/to:  "live", ID de snapshot ou instante RFC3339 (último snapshot até ele); to padrão "live"
+//	fromNamespace/toNamespace: compara dois namespaces, pareando recursos por kind/nome
+//	namespace: recorta os dois lados para um namespace (fora do modo acima)
+//	view=overlay: responde com o grafo do React Flow com data.diff em cada nó/aresta
+//
+// O lado ao vivo usa snapshot.GraphOptions, para comparar com os snapshots em
+// pé de igualdade; os demais parâmetros de construção/filtro respondem 400.
+//
+// Ex: /api/v1/topology/1/diff?from=42&to=live
+// Ex: /api/v1/topology/1/diff?fromNamespace=staging&toNamespace=production&view=overlay
+func topologyDiffHandler(cfg *config.Config, clusters *k8s.ClusterManager) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		opts, ok := topologyOptionsFromQuery(c)
+		if !ok || rejectGraphBuildParams(c, "diffs") {
+			return
+		}
+
+		fromNs, toNs := c.Query("fromNamespace"), c.Query("toNamespace")
+		if (fromNs == "") != (toNs == "") {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "fromNamespace e toNamespace devem ser informados juntos"})
+			return
+		}
+		diffOpts := k8s.DiffOptions{IgnoreNamespace: fromNs != ""}
+		if !diffOpts.IgnoreNamespace {
+			fromNs, toNs = opts.Namespace, opts.Namespace
+		}
+
+		fromRef := c.Query("from")
+		toRef := c.DefaultQuery("to", "live")
+		if fromRef == "" {
+			if !diffOpts.IgnoreNamespace {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "from é obrigatório (live, ID de snapshot ou RFC3339)"})
+				return
+			}
+			fromRef = "live"
+		}
+
+		cluster, err := getClusterFromRequest(c, "clusterID")
+		if err != nil {
+			return
+		}
+
+		before, fromSide, ok := loadDiffSide(c, cfg, clusters, cluster, fromRef)
+		if !ok {
+			return
+		}
+		after, toSide, ok := loadDiffSide(c, cfg, clusters, cluster, toRef)
+		if !ok {
+			return
+		}
+		before = k8s.FilterNamespace(before, fromNs)
+		after = k8s.FilterNamespace(after, toNs)
+		if diffOpts.IgnoreNamespace {
+			fromSide.Namespace, toSide.Namespace = fromNs, toNs
+		}
+
+		diff := k8s.DiffGraphs(before, after, diffOpts)
+		if c.Query("view") == "overlay" {
+			c.JSON(http.StatusOK, k8s.DiffOverlay(before, after, diff, diffOpts, opts.Layout))
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{
+			"from": fromSide,
+			"to":   toSide,
+			"diff": diff,
+		})
+	}
+}
+
+// loadDiffSide resolve uma referência de from/to para um grafo. Em caso de
+// erro já responde à requisição e retorna ok=false.
+func loadDiffSide(c *gin.Context, cfg *config.Config, clusters *k8s.ClusterManager, cluster *models.Cluster, ref string) (*k8s.ClusterGraph, diffSide, bool) {
+	if ref == "live" {
+		kubeconfig, err := crypto.DecryptAES(cfg.AESKey, cluster.EncryptedKubeconfig)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "erro ao decifrar kubeconfig"})
+			return nil, diffSide{}, false
+		}
+		clusterCache, err := clusters.Get(cluster, kubeconfig)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "erro ao criar client Kubernetes"})
+			return nil, diffSide{}, false
+		}
+
+		waitCtx, cancel := context.WithTimeout(c.Request.Context(), cacheSyncWait)
+		defer cancel()
+		if !clusterCache.WaitForSync(waitCtx) {
+			c.JSON(http.StatusAccepted, gin.H{"cache": clusterCache.Status()})
+			return nil, diffSide{}, false
+		}
+
+		// Mesmas opções dos snapshots, para que a comparação seja justa
+		g, err := clusterCache.BuildClusterGraph(snapshot.GraphOptions)
+		if !graphUsable(err) {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "erro ao construir grafo"})
+			return nil, diffSide{}, false
+		}
+		now := time.Now().UTC()
+		return g, diffSide{Source: "live", Time: &now}, true
+	}
+
+	var snap *models.TopologySnapshot
+	var err error
+	if id, convErr := strconv.ParseUint(ref, 10, 64); convErr == nil {
+		snap = &models.TopologySnapshot{}
+		err = db.DB.Where("id = ? AND cluster_id = ?", id, cluster.ID).First(snap).Error
+	} else if at, parseErr := time.Parse(time.RFC3339, ref); parseErr == nil {
+		snap, err = snapshot.At(cluster.ID, at)
+	} else {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "referência inválida: use live, ID de snapshot ou RFC3339"})
+		return nil, diffSide{}, false
+	}
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "snapshot não encontrado: " + ref})
+		return nil, diffSide{}, false
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "erro ao buscar snapshot"})
+		return nil, diffSide{}, false
+	}
+
+	g, err := snapshot.Load(snap)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "erro ao ler snapshot"})
+		return nil, diffSide{}, false
+	}
+	created := snap.CreatedAt.UTC()
+	return g, diffSide{Source: "snapshot", SnapshotID: snap.ID, Time: &created}, true
+}
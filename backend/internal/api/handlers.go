@@ -1,14 +1,19 @@
 package api
 
 import (
+	"bytes"
 	"context"
 	"encoding/base64"
+	"errors"
+	"fmt"
 	"net/http"
 	"os"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/gin-gonic/gin"
+	corev1 "k8s.io/api/core/v1"
 	"k8s.io/client-go/kubernetes" // Importante para o tipo de retorno do helper
 
 	"github.com/example/vkube-topology/backend/internal/auth"
@@ -137,15 +142,30 @@ func meHandler() gin.HandlerFunc {
 // =================================================================================
 
 type clusterDTO struct {
-	ID          uint   `json:"id"`
-	Name        string `json:"name" binding:"required"`
-	Description string `json:"description"`
+	ID             uint     `json:"id"`
+	Name           string   `json:"name" binding:"required"`
+	Description    string   `json:"description"`
+	OwnerResources []string `json:"ownerResources"`
 }
 
 type createClusterRequest struct {
 	Name             string `json:"name" binding:"required"`
 	Description      string `json:"description"`
 	KubeconfigBase64 string `json:"kubeconfigBase64" binding:"required"`
+	// GVRs incluídos no grafo de owner references (ex: argoproj.io/v1alpha1/rollouts).
+	// No update, ausente mantém a lista atual.
+	OwnerResources []string `json:"ownerResources"`
+}
+
+// joinOwnerResources valida os GVRs recebidos e os junta no formato de
+// models.Cluster.OwnerResources.
+func joinOwnerResources(list []string) (string, error) {
+	for _, s := range list {
+		if _, err := k8s.ParseGVR(s); err != nil {
+			return "", err
+		}
+	}
+	return strings.Join(list, ","), nil
 }
 
 func listClustersHandler(cfg *config.Config) gin.HandlerFunc {
@@ -159,9 +179,10 @@ func listClustersHandler(cfg *config.Config) gin.HandlerFunc {
 		resp := make([]clusterDTO, 0, len(clusters))
 		for _, cl := range clusters {
 			resp = append(resp, clusterDTO{
-				ID:          cl.ID,
-				Name:        cl.Name,
-				Description: cl.Description,
+				ID:             cl.ID,
+				Name:           cl.Name,
+				Description:    cl.Description,
+				OwnerResources: splitList(cl.OwnerResources),
 			})
 		}
 		c.JSON(http.StatusOK, resp)
@@ -191,11 +212,18 @@ func createClusterHandler(cfg *config.Config) gin.HandlerFunc {
 			return
 		}
 
+		ownerResources, err := joinOwnerResources(req.OwnerResources)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
 		cluster := models.Cluster{
 			Name:                req.Name,
 			Description:         req.Description,
 			OwnerUsername:       claims.Username,
 			EncryptedKubeconfig: ciphertext,
+			OwnerResources:      ownerResources,
 		}
 
 		if err := db.DB.Create(&cluster).Error; err != nil {
@@ -204,9 +232,10 @@ func createClusterHandler(cfg *config.Config) gin.HandlerFunc {
 		}
 
 		c.JSON(http.StatusCreated, clusterDTO{
-			ID:          cluster.ID,
-			Name:        cluster.Name,
-			Description: cluster.Description,
+			ID:             cluster.ID,
+			Name:           cluster.Name,
+			Description:    cluster.Description,
+			OwnerResources: splitList(cluster.OwnerResources),
 		})
 	}
 }
@@ -230,14 +259,15 @@ func getClusterHandler(cfg *config.Config) gin.HandlerFunc {
 		}
 
 		c.JSON(http.StatusOK, clusterDTO{
-			ID:          cluster.ID,
-			Name:        cluster.Name,
-			Description: cluster.Description,
+			ID:             cluster.ID,
+			Name:           cluster.Name,
+			Description:    cluster.Description,
+			OwnerResources: splitList(cluster.OwnerResources),
 		})
 	}
 }
 
-func updateClusterHandler(cfg *config.Config) gin.HandlerFunc {
+func updateClusterHandler(cfg *config.Config, clusters *k8s.ClusterManager) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		idStr := c.Param("id")
 		id, err := strconv.Atoi(idStr)
@@ -262,6 +292,14 @@ func updateClusterHandler(cfg *config.Config) gin.HandlerFunc {
 
 		cluster.Name = req.Name
 		cluster.Description = req.Description
+		if req.OwnerResources != nil {
+			ownerResources, err := joinOwnerResources(req.OwnerResources)
+			if err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+				return
+			}
+			cluster.OwnerResources = ownerResources
+		}
 
 		if req.KubeconfigBase64 != "" {
 			kubeconfig, err := base64.StdEncoding.DecodeString(req.KubeconfigBase64)
@@ -281,16 +319,18 @@ func updateClusterHandler(cfg *config.Config) gin.HandlerFunc {
 			c.JSON(http.StatusInternalServerError, gin.H{"error": "erro ao atualizar cluster"})
 			return
 		}
+		clusters.Evict(cluster.ID)
 
 		c.JSON(http.StatusOK, clusterDTO{
-			ID:          cluster.ID,
-			Name:        cluster.Name,
-			Description: cluster.Description,
+			ID:             cluster.ID,
+			Name:           cluster.Name,
+			Description:    cluster.Description,
+			OwnerResources: splitList(cluster.OwnerResources),
 		})
 	}
 }
 
-func deleteClusterHandler(cfg *config.Config) gin.HandlerFunc {
+func deleteClusterHandler(cfg *config.Config, clusters *k8s.ClusterManager) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		idStr := c.Param("id")
 		id, err := strconv.Atoi(idStr)
@@ -305,6 +345,7 @@ func deleteClusterHandler(cfg *config.Config) gin.HandlerFunc {
 			c.JSON(http.StatusInternalServerError, gin.H{"error": "erro ao remover cluster"})
 			return
 		}
+		clusters.Evict(uint(id))
 		c.Status(http.StatusNoContent)
 	}
 }
@@ -378,52 +419,263 @@ func getResourceLogsHandler(cfg *config.Config) gin.HandlerFunc {
 	}
 }
 
+// getAPIResourcesHandler lista os recursos listáveis do cluster via discovery,
+// para o admin escolher os ownerResources (grafo de owner references).
+func getAPIResourcesHandler(cfg *config.Config) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		client, err := getK8sClientFromRequest(c, cfg)
+		if err != nil {
+			return
+		}
+
+		resources, err := k8s.DiscoverAPIResources(client.Discovery())
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "erro ao consultar discovery: " + err.Error()})
+			return
+		}
+
+		c.JSON(http.StatusOK, resources)
+	}
+}
+
 // =================================================================================
 // TOPOLOGY HANDLERS
 // =================================================================================
 
-func topologyHandler(cfg *config.Config) gin.HandlerFunc {
+// cacheSyncWait é quanto uma requisição espera o aquecimento do cache antes de
+// responder 202 com o status de sincronização.
+const cacheSyncWait = 3 * time.Second
+
+// topologyOptionsFromQuery lê os parâmetros de construção do grafo comuns às
+// rotas de topologia. Em caso de parâmetro inválido responde 400 e retorna false.
+func topologyOptionsFromQuery(c *gin.Context) (k8s.TopologyOptions, bool) {
+	ns := c.Query("namespace")
+	if ns == "" {
+		ns = "all"
+	}
+
+	layout := c.DefaultQuery("layout", k8s.LayoutLayered)
+	if err := k8s.ValidateLayout(layout); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return k8s.TopologyOptions{}, false
+	}
+
+	opts := k8s.TopologyOptions{
+		Namespace:       ns,
+		Layout:          layout,
+		ConfigEdges:     c.DefaultQuery("configEdges", k8s.ConfigEdgesWorkload),
+		NetworkPolicies: c.Query("netpol") == "true",
+		Selector:        c.Query("selector"),
+		FieldSelector:   c.Query("fieldSelector"),
+		Kinds:           splitList(c.Query("kinds")),
+		ExcludeKinds:    splitList(c.Query("excludeKinds")),
+		NameRegex:       c.Query("name"),
+		Detail:          c.Query("detail"),
+		ShowHistory:     c.Query("history") == "true",
+		OwnerRefs:       c.Query("ownerRefs") == "true",
+		InferCalls:      c.Query("inferCalls") == "true",
+	}
+	if err := k8s.ValidateConfigEdges(opts.ConfigEdges); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return k8s.TopologyOptions{}, false
+	}
+	if err := k8s.ValidateDetail(opts.Detail); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return k8s.TopologyOptions{}, false
+	}
+	if err := k8s.ValidateFilters(opts); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return k8s.TopologyOptions{}, false
+	}
+	return opts, true
+}
+
+// graphBuildParams são os parâmetros de query que mudam a construção do grafo
+// (além de namespace e layout). Grafos já gravados (snapshots) foram montados
+// com snapshot.GraphOptions e não podem aplicá-los.
+var graphBuildParams = []string{
+	"configEdges", "netpol", "selector", "fieldSelector", "kinds", "excludeKinds",
+	"name", "detail", "history", "ownerRefs", "inferCalls",
+}
+
+// rejectGraphBuildParams responde 400 se a requisição trouxer algum de
+// graphBuildParams, para que não sejam ignorados em silêncio.
+func rejectGraphBuildParams(c *gin.Context, where string) bool {
+	for _, p := range graphBuildParams {
+		if _, ok := c.GetQuery(p); ok {
+			c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("%s não é suportado em %s (só namespace e layout)", p, where)})
+			return true
+		}
+	}
+	return false
+}
+
+// splitList separa valores de query em lista ("a,b" -> [a b]), ignorando vazios.
+func splitList(v string) []string {
+	var out []string
+	for _, item := range strings.Split(v, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			out = append(out, item)
+		}
+	}
+	return out
+}
+
+// exporterFromQuery lê format= (dot, graphml, mermaid, cytoscape, json, svg).
+// Sem format retorna nil: a resposta segue no formato do React Flow.
+func exporterFromQuery(c *gin.Context) (k8s.Exporter, bool) {
+	format := c.Query("format")
+	if format == "" {
+		return nil, true
+	}
+	exporter, err := k8s.GetExporter(format)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return nil, false
+	}
+	return exporter, true
+}
+
+// writeExport responde com o grafo serializado pelo exporter.
+func writeExport(c *gin.Context, exporter k8s.Exporter, g *k8s.ClusterGraph, opts k8s.TopologyOptions) {
+	var buf bytes.Buffer
+	if err := exporter.Export(&buf, g, k8s.ExportOptions{Layout: opts.Layout}); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "erro ao exportar grafo"})
+		return
+	}
+	c.Data(http.StatusOK, exporter.ContentType(), buf.Bytes())
+}
+
+func topologyHandler(cfg *config.Config, clusters *k8s.ClusterManager) gin.HandlerFunc {
 	return func(c *gin.Context) {
-		idStr := c.Param("clusterID")
-		id, err := strconv.Atoi(idStr)
+		opts, ok := topologyOptionsFromQuery(c)
+		if !ok {
+			return
+		}
+		exporter, ok := exporterFromQuery(c)
+		if !ok {
+			return
+		}
+		if exporter != nil && c.Query("since") != "" {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "format não pode ser usado com since"})
+			return
+		}
+		if c.Query("at") != "" && c.Query("since") != "" {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "at não pode ser usado com since"})
+			return
+		}
+
+		// ?at=<RFC3339>: topologia gravada no último snapshot até o instante
+		if c.Query("at") != "" {
+			cluster, err := getClusterFromRequest(c, "clusterID")
+			if err != nil {
+				return
+			}
+			snapshotAtHandler(c, cluster, opts, exporter)
+			return
+		}
+
+		clusterCache, err := getClusterCacheFromRequest(c, cfg, clusters)
 		if err != nil {
-			c.JSON(http.StatusBadRequest, gin.H{"error": "id inválido"})
 			return
 		}
 
-		ns := c.Query("namespace")
-		if ns == "" {
-			ns = "all"
+		// Primeiro acesso: os informers ainda estão fazendo o List inicial
+		waitCtx, cancel := context.WithTimeout(c.Request.Context(), cacheSyncWait)
+		defer cancel()
+		if !clusterCache.WaitForSync(waitCtx) {
+			c.JSON(http.StatusAccepted, gin.H{"cache": clusterCache.Status()})
+			return
 		}
 
-		claimsVal, _ := c.Get("user")
-		claims := claimsVal.(*auth.Claims)
+		// ?since=<revision>: apenas o delta (ou o grafo completo em delta.graph se a revisão expirou)
+		if sinceStr := c.Query("since"); sinceStr != "" {
+			since, err := strconv.ParseUint(sinceStr, 10, 64)
+			if err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "since deve ser um número de revisão"})
+				return
+			}
+			delta, err := clusterCache.BuildTopologyDelta(opts, since)
+			if !graphUsable(err) {
+				c.JSON(http.StatusInternalServerError, gin.H{"error": "erro ao construir grafo"})
+				return
+			}
+			c.JSON(http.StatusOK, delta)
+			return
+		}
 
-		var cluster models.Cluster
-		if err := db.DB.Where("id = ? AND owner_username = ?", id, claims.Username).First(&cluster).Error; err != nil {
-			c.JSON(http.StatusNotFound, gin.H{"error": "cluster não encontrado"})
+		// ?format=: grafo de domínio exportado (DOT, GraphML...), sem revisão
+		if exporter != nil {
+			g, err := clusterCache.BuildClusterGraph(opts)
+			if !graphUsable(err) {
+				c.JSON(http.StatusInternalServerError, gin.H{"error": "erro ao construir grafo"})
+				return
+			}
+			writeExport(c, exporter, g, opts)
 			return
 		}
 
-		kubeconfig, err := crypto.DecryptAES(cfg.AESKey, cluster.EncryptedKubeconfig)
+		graph, err := clusterCache.BuildTopologyGraph(opts)
+		// Resultado parcial: o grafo segue com os avisos em graph.Warnings
+		if !graphUsable(err) {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "erro ao construir grafo"})
+			return
+		}
+
+		c.JSON(http.StatusOK, graph)
+	}
+}
+
+// reachabilityHandler responde se um pod alcança outro segundo as NetworkPolicies.
+// Ex: /api/v1/topology/1/reachability?from=default/web-0&to=db/postgres-0&port=5432&protocol=TCP
+func reachabilityHandler(cfg *config.Config, clusters *k8s.ClusterManager) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		from := c.Query("from")
+		to := c.Query("to")
+		if !strings.Contains(from, "/") || !strings.Contains(to, "/") {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "from e to são obrigatórios no formato namespace/pod"})
+			return
+		}
+		protocol := corev1.Protocol(strings.ToUpper(c.DefaultQuery("protocol", string(corev1.ProtocolTCP))))
+		if protocol != corev1.ProtocolTCP && protocol != corev1.ProtocolUDP && protocol != corev1.ProtocolSCTP {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "protocol deve ser TCP, UDP ou SCTP"})
+			return
+		}
+
+		clusterCache, err := getClusterCacheFromRequest(c, cfg, clusters)
 		if err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "erro ao decifrar kubeconfig"})
 			return
 		}
 
-		client, err := k8s.NewClient(kubeconfig)
+		waitCtx, cancel := context.WithTimeout(c.Request.Context(), cacheSyncWait)
+		defer cancel()
+		if !clusterCache.WaitForSync(waitCtx) {
+			c.JSON(http.StatusAccepted, gin.H{"cache": clusterCache.Status()})
+			return
+		}
+
+		result, err := clusterCache.Reachability(from, to, c.Query("port"), protocol)
+		if errors.Is(err, k8s.ErrNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
 		if err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "erro ao criar client Kubernetes"})
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "erro ao avaliar NetworkPolicies"})
 			return
 		}
 
-		graph, err := k8s.BuildTopologyGraph(context.Background(), client, ns)
+		c.JSON(http.StatusOK, result)
+	}
+}
+
+func topologyStatusHandler(cfg *config.Config, clusters *k8s.ClusterManager) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		cluster, err := getClusterFromRequest(c, "clusterID")
 		if err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "erro ao construir grafo"})
 			return
 		}
 
-		c.JSON(http.StatusOK, graph)
+		c.JSON(http.StatusOK, clusters.Status(cluster.ID))
 	}
 }
 
@@ -431,6 +683,55 @@ func topologyHandler(cfg *config.Config) gin.HandlerFunc {
 // HELPERS
 // =================================================================================
 
+// graphUsable indica se o grafo retornado pode ser usado: err nil ou apenas
+// *k8s.PartialResultError (grafo incompleto, com os avisos no próprio grafo).
+func graphUsable(err error) bool {
+	var partial *k8s.PartialResultError
+	return err == nil || errors.As(err, &partial)
+}
+
+// getClusterFromRequest busca o cluster pelo parâmetro de rota informado e verifica se pertence ao usuário
+func getClusterFromRequest(c *gin.Context, param string) (*models.Cluster, error) {
+	id, err := strconv.Atoi(c.Param(param))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "id inválido"})
+		return nil, err
+	}
+
+	claimsVal, _ := c.Get("user")
+	claims := claimsVal.(*auth.Claims)
+
+	var cluster models.Cluster
+	if err := db.DB.Where("id = ? AND owner_username = ?", id, claims.Username).First(&cluster).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "cluster não encontrado"})
+		return nil, err
+	}
+
+	return &cluster, nil
+}
+
+// getClusterCacheFromRequest retorna o cache de informers do cluster em :clusterID, iniciando-o se necessário
+func getClusterCacheFromRequest(c *gin.Context, cfg *config.Config, clusters *k8s.ClusterManager) (*k8s.ClusterCache, error) {
+	cluster, err := getClusterFromRequest(c, "clusterID")
+	if err != nil {
+		return nil, err
+	}
+
+	kubeconfig, err := crypto.DecryptAES(cfg.AESKey, cluster.EncryptedKubeconfig)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "erro ao decifrar kubeconfig"})
+		return nil, err
+	}
+
+	clusterCache, err := clusters.Get(cluster, kubeconfig)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "erro ao criar client Kubernetes"})
+		return nil, err
+	}
+
+	return clusterCache, nil
+}
+
 // getK8sClientFromRequest busca o cluster pelo ID na URL, verifica permissão e retorna o client K8s
 func getK8sClientFromRequest(c *gin.Context, cfg *config.Config) (*kubernetes.Clientset, error) {
 	// Pega o parametro :id da rota
@@ -0,0 +1,163 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/example/vkube-topology/backend/internal/auth"
+	"github.com/example/vkube-topology/backend/internal/config"
+	"github.com/example/vkube-topology/backend/internal/crypto"
+	"github.com/example/vkube-topology/backend/internal/db"
+	"github.com/example/vkube-topology/backend/internal/k8s"
+	"github.com/example/vkube-topology/backend/internal/models"
+)
+
+// =================================================================================
+// MULTI-CLUSTER
+// =================================================================================
+
+// errCacheSyncing indica um cluster cujo cache não terminou o List inicial a tempo.
+var errCacheSyncing = errors.New("cache do cluster ainda sincronizando")
+
+// multiClusterTopologyHandler devolve a topologia combinada de vários clusters
+// do usuário. Os grafos são construídos em paralelo; um cluster que falha (ou
+// não sincroniza a tempo) vira um aviso e um grupo vazio, sem derrubar os demais.
+// Aceita os parâmetros de construção/filtro e format= da rota de um cluster.
+// Ex: /api/v1/topology?clusters=1,2,3&namespace=shop
+func multiClusterTopologyHandler(cfg *config.Config, clusters *k8s.ClusterManager) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		opts, ok := topologyOptionsFromQuery(c)
+		if !ok {
+			return
+		}
+		exporter, ok := exporterFromQuery(c)
+		if !ok {
+			return
+		}
+
+		var ids []uint
+		seen := map[uint]bool{}
+		for _, s := range splitList(c.Query("clusters")) {
+			id, err := strconv.ParseUint(s, 10, 64)
+			if err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "clusters deve ser uma lista de ids (ex: 1,2,3)"})
+				return
+			}
+			if !seen[uint(id)] {
+				seen[uint(id)] = true
+				ids = append(ids, uint(id))
+			}
+		}
+		if len(ids) == 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "clusters é obrigatório (ex: 1,2,3)"})
+			return
+		}
+
+		claimsVal, _ := c.Get("user")
+		claims := claimsVal.(*auth.Claims)
+
+		var found []models.Cluster
+		if err := db.DB.Where("id IN ? AND owner_username = ?", ids, claims.Username).Find(&found).Error; err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "erro ao buscar clusters"})
+			return
+		}
+		byID := make(map[uint]*models.Cluster, len(found))
+		for i := range found {
+			byID[found[i].ID] = &found[i]
+		}
+		for _, id := range ids {
+			if byID[id] == nil {
+				c.JSON(http.StatusNotFound, gin.H{"error": fmt.Sprintf("cluster não encontrado: %d", id)})
+				return
+			}
+		}
+
+		// Um goroutine por cluster; cada um escreve só na sua posição de parts
+		parts := make([]k8s.ClusterPart, len(ids))
+		var wg sync.WaitGroup
+		for i, id := range ids {
+			cluster := byID[id]
+			parts[i] = k8s.ClusterPart{ID: strconv.FormatUint(uint64(id), 10), Name: cluster.Name}
+			wg.Add(1)
+			go func(part *k8s.ClusterPart) {
+				defer wg.Done()
+				part.Graph, part.Err = buildClusterPart(c.Request.Context(), cfg, clusters, cluster, opts)
+			}(&parts[i])
+		}
+		wg.Wait()
+
+		merged := k8s.MergeClusterGraphs(parts)
+		if exporter != nil {
+			writeExport(c, exporter, merged, opts)
+			return
+		}
+		c.JSON(http.StatusOK, k8s.RenderGraph(merged, opts.Layout))
+	}
+}
+
+// buildClusterPart constrói o grafo de um cluster da visão combinada.
+// Resultados parciais seguem com os avisos no próprio grafo.
+func buildClusterPart(ctx context.Context, cfg *config.Config, clusters *k8s.ClusterManager, cluster *models.Cluster, opts k8s.TopologyOptions) (*k8s.ClusterGraph, error) {
+	kubeconfig, err := crypto.DecryptAES(cfg.AESKey, cluster.EncryptedKubeconfig)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao decifrar kubeconfig: %w", err)
+	}
+
+	clusterCache, err := clusters.Get(cluster, kubeconfig)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao criar client Kubernetes: %w", err)
+	}
+
+	waitCtx, cancel := context.WithTimeout(ctx, cacheSyncWait)
+	defer cancel()
+	if !clusterCache.WaitForSync(waitCtx) {
+		return nil, cacheSyncError(clusterCache.Status())
+	}
+
+	g, err := clusterCache.BuildClusterGraph(opts)
+	if !graphUsable(err) {
+		return nil, err
+	}
+	return g, nil
+}
+
+// cacheSyncError acrescenta a errCacheSyncing o estado do cache e os erros de
+// list/watch dos informers (ex: API inacessível, credencial recusada), para o
+// aviso dizer por que o cluster não respondeu. Kinds com o mesmo erro são
+// agrupados; sem erros, lista os informers pendentes.
+func cacheSyncError(status k8s.CacheStatus) error {
+	details := []string{"estado " + status.State}
+	byMessage := map[string][]string{}
+	for kind, msg := range status.Errors {
+		byMessage[msg] = append(byMessage[msg], kind)
+	}
+	messages := make([]string, 0, len(byMessage))
+	for msg, kinds := range byMessage {
+		sort.Strings(kinds)
+		messages = append(messages, strings.Join(kinds, ", ")+": "+msg)
+	}
+	sort.Strings(messages)
+	details = append(details, messages...)
+
+	if len(messages) == 0 {
+		var pending []string
+		for kind, synced := range status.Informers {
+			if !synced {
+				pending = append(pending, kind)
+			}
+		}
+		sort.Strings(pending)
+		if len(pending) > 0 {
+			details = append(details, "pendentes: "+strings.Join(pending, ", "))
+		}
+	}
+	return fmt.Errorf("%w (%s)", errCacheSyncing, strings.Join(details, "; "))
+}
@@ -0,0 +1,170 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/example/vkube-topology/backend/internal/config"
+	"github.com/example/vkube-topology/backend/internal/k8s"
+)
+
+// =================================================================================
+// NEIGHBORHOOD / IMPACT
+// =================================================================================
+
+// maxNeighborhoodDepth limita depth para que a resposta continue pequena.
+const maxNeighborhoodDepth = 20
+
+// neighborhoodHandler devolve o subgrafo alcançável a partir de um nó.
+//
+//	depth: passos a partir do nó (padrão 1; 0 = sem limite, até maxNeighborhoodDepth)
+//	direction: down (sentido das arestas), up ou both (padrão both)
+//	mode=impact: tudo que seria afetado se o nó falhasse, com a lista em "affected"
+//
+// Aceita também os parâmetros de construção/filtro e format= da rota de topologia.
+// Ex: /api/v1/topology/1/nodes/svc:default:web/neighborhood?depth=2&direction=both
+// Ex: /api/v1/topology/1/nodes/node:worker-1/neighborhood?mode=impact
+func neighborhoodHandler(cfg *config.Config, clusters *k8s.ClusterManager) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		opts, ok := topologyOptionsFromQuery(c)
+		if !ok {
+			return
+		}
+		exporter, ok := exporterFromQuery(c)
+		if !ok {
+			return
+		}
+
+		impact := c.Query("mode") == "impact"
+		if mode := c.Query("mode"); mode != "" && !impact {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "mode deve ser impact ou vazio"})
+			return
+		}
+
+		depth := 1
+		if impact {
+			depth = 0 // impacto segue a cadeia inteira por padrão
+		}
+		if d := c.Query("depth"); d != "" {
+			v, err := strconv.Atoi(d)
+			if err != nil || v < 0 {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "depth deve ser um inteiro >= 0"})
+				return
+			}
+			depth = v
+		}
+		if depth == 0 || depth > maxNeighborhoodDepth {
+			depth = maxNeighborhoodDepth
+		}
+
+		direction := c.DefaultQuery("direction", k8s.DirectionBoth)
+		if err := k8s.ValidateDirection(direction); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		clusterCache, err := getClusterCacheFromRequest(c, cfg, clusters)
+		if err != nil {
+			return
+		}
+
+		waitCtx, cancel := context.WithTimeout(c.Request.Context(), cacheSyncWait)
+		defer cancel()
+		if !clusterCache.WaitForSync(waitCtx) {
+			c.JSON(http.StatusAccepted, gin.H{"cache": clusterCache.Status()})
+			return
+		}
+
+		g, err := clusterCache.BuildClusterGraph(opts)
+		if !graphUsable(err) {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "erro ao construir grafo"})
+			return
+		}
+
+		nodeID := c.Param("nodeID")
+		var sub *k8s.ClusterGraph
+		var affected []k8s.ImpactedNode
+		if impact {
+			sub, affected, err = k8s.Impact(g, nodeID, depth)
+		} else {
+			sub, err = k8s.Neighborhood(g, nodeID, depth, direction)
+		}
+		if errors.Is(err, k8s.ErrNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		if exporter != nil {
+			writeExport(c, exporter, sub, opts)
+			return
+		}
+		if impact {
+			c.JSON(http.StatusOK, gin.H{
+				"root":     nodeID,
+				"affected": affected,
+				"graph":    k8s.RenderGraph(sub, opts.Layout),
+			})
+			return
+		}
+		c.JSON(http.StatusOK, k8s.RenderGraph(sub, opts.Layout))
+	}
+}
+
+// =================================================================================
+// EXPAND (NÍVEL DE DETALHE)
+// =================================================================================
+
+// expandHandler devolve os filhos diretos de um nó agregado pelo nível de
+// detalhe (detail= igual ao da visão), para o cliente expandir só aquele nó.
+// Desce de nível até achar filhos: um StatefulSet na visão workload continua
+// agregado em replicaset e só mostra seus Pods em pod.
+// Ex: /api/v1/topology/1/nodes/deploy:default:web/children?detail=workload
+func expandHandler(cfg *config.Config, clusters *k8s.ClusterManager) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		opts, ok := topologyOptionsFromQuery(c)
+		if !ok {
+			return
+		}
+
+		clusterCache, err := getClusterCacheFromRequest(c, cfg, clusters)
+		if err != nil {
+			return
+		}
+
+		waitCtx, cancel := context.WithTimeout(c.Request.Context(), cacheSyncWait)
+		defer cancel()
+		if !clusterCache.WaitForSync(waitCtx) {
+			c.JSON(http.StatusAccepted, gin.H{"cache": clusterCache.Status()})
+			return
+		}
+
+		viewDetail := opts.Detail
+		var sub *k8s.ClusterGraph
+		for level := k8s.NextDetail(viewDetail); ; level = k8s.NextDetail(level) {
+			opts.Detail = level
+			g, err := clusterCache.BuildClusterGraph(opts)
+			if !graphUsable(err) {
+				c.JSON(http.StatusInternalServerError, gin.H{"error": "erro ao construir grafo"})
+				return
+			}
+			sub, err = k8s.ExpandNode(g, c.Param("nodeID"), viewDetail)
+			if errors.Is(err, k8s.ErrNotFound) {
+				c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+				return
+			}
+			if len(sub.Nodes) > 1 || level == k8s.DetailPod {
+				break
+			}
+		}
+
+		c.JSON(http.StatusOK, k8s.RenderGraph(sub, opts.Layout))
+	}
+}
@@ -7,10 +7,12 @@ import (
 
     "github.com/example/vkube-topology/backend/internal/auth"
     "github.com/example/vkube-topology/backend/internal/config"
+    "github.com/example/vkube-topology/backend/internal/k8s"
+    "github.com/example/vkube-topology/backend/internal/snapshot"
 )
 
 // RegisterRoutes registra todas as rotas /api/v1.
-func RegisterRoutes(r *gin.Engine, cfg *config.Config) {
+func RegisterRoutes(r *gin.Engine, cfg *config.Config, clusters *k8s.ClusterManager, snapshots *snapshot.Service) {
     api := r.Group("/api/v1")
 
     // Auth
@@ -29,22 +31,40 @@ func RegisterRoutes(r *gin.Engine, cfg *config.Config) {
         
         // Rotas específicas de um Cluster
         clusterGroup.GET("/:id", getClusterHandler(cfg))
-        clusterGroup.PUT("/:id", auth.RequireRole("admin"), updateClusterHandler(cfg))
-        clusterGroup.DELETE("/:id", auth.RequireRole("admin"), deleteClusterHandler(cfg))
+        clusterGroup.PUT("/:id", auth.RequireRole("admin"), updateClusterHandler(cfg, clusters))
+        clusterGroup.DELETE("/:id", auth.RequireRole("admin"), deleteClusterHandler(cfg, clusters))
 
         // --- NOVAS ROTAS DE RECURSOS (YAML & LOGS) ---
         // Ex: /api/v1/clusters/1/resources/yaml?kind=Pod&name=meu-pod&namespace=default
         clusterGroup.GET("/:id/resources/yaml", getResourceYAMLHandler(cfg))
         clusterGroup.GET("/:id/resources/logs", getResourceLogsHandler(cfg))
+
+        // Recursos disponíveis para o grafo de owner references (PUT /:id com ownerResources)
+        clusterGroup.GET("/:id/api-resources", auth.RequireRole("admin"), getAPIResourcesHandler(cfg))
     }
 
     // Topologia
     topologyGroup := api.Group("/topology")
     topologyGroup.Use(auth.AuthMiddleware(cfg))
     {
-        topologyGroup.GET("/:clusterID", topologyHandler(cfg))
+        topologyGroup.GET("", multiClusterTopologyHandler(cfg, clusters)) // ?clusters=1,2,3
+        topologyGroup.GET("/:clusterID", topologyHandler(cfg, clusters))
+        topologyGroup.GET("/:clusterID/status", topologyStatusHandler(cfg, clusters))
+        topologyGroup.GET("/:clusterID/reachability", reachabilityHandler(cfg, clusters))
+        topologyGroup.GET("/:clusterID/nodes/:nodeID/neighborhood", neighborhoodHandler(cfg, clusters))
+        topologyGroup.GET("/:clusterID/nodes/:nodeID/children", expandHandler(cfg, clusters))
+        topologyGroup.POST("/:clusterID/stream-token", streamTokenHandler(cfg))
+
+        // Snapshots (histórico)
+        topologyGroup.GET("/:clusterID/snapshots", listSnapshotsHandler())
+        topologyGroup.POST("/:clusterID/snapshots", createSnapshotHandler(snapshots))
+        topologyGroup.GET("/:clusterID/snapshots/:snapshotID", getSnapshotHandler())
+        topologyGroup.GET("/:clusterID/diff", topologyDiffHandler(cfg, clusters))
     }
 
+    // Stream SSE: EventSource não envia headers, então aceita também ?token= (ver stream-token)
+    api.GET("/topology/:clusterID/stream", auth.StreamAuthMiddleware(cfg), topologyStreamHandler(cfg, clusters))
+
     // Healthcheck simples
     r.GET("/healthz", func(c *gin.Context) {
         c.JSON(http.StatusOK, gin.H{"status": "ok"})
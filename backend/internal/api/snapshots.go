@@ -0,0 +1,153 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+
+	"github.com/example/vkube-topology/backend/internal/db"
+	"github.com/example/vkube-topology/backend/internal/k8s"
+	"github.com/example/vkube-topology/backend/internal/models"
+	"github.com/example/vkube-topology/backend/internal/snapshot"
+)
+
+// =================================================================================
+// TOPOLOGY SNAPSHOTS
+// =================================================================================
+
+// listSnapshotsHandler lista os snapshots do cluster (sem o grafo), do mais recente
+// para o mais antigo. Filtros opcionais: from/to (RFC3339) e limit (padrão 100).
+func listSnapshotsHandler() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		cluster, err := getClusterFromRequest(c, "clusterID")
+		if err != nil {
+			return
+		}
+
+		query := db.DB.Omit("graph").Where("cluster_id = ?", cluster.ID)
+		if from := c.Query("from"); from != "" {
+			t, err := time.Parse(time.RFC3339, from)
+			if err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "from deve estar em RFC3339"})
+				return
+			}
+			query = query.Where("created_at >= ?", t)
+		}
+		if to := c.Query("to"); to != "" {
+			t, err := time.Parse(time.RFC3339, to)
+			if err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "to deve estar em RFC3339"})
+				return
+			}
+			query = query.Where("created_at <= ?", t)
+		}
+		limit := 100
+		if l, err := strconv.Atoi(c.Query("limit")); err == nil && l > 0 && l <= 1000 {
+			limit = l
+		}
+
+		var snaps []models.TopologySnapshot
+		if err := query.Order("created_at DESC").Limit(limit).Find(&snaps).Error; err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "erro ao listar snapshots"})
+			return
+		}
+		c.JSON(http.StatusOK, snaps)
+	}
+}
+
+// createSnapshotHandler tira um snapshot sob demanda.
+func createSnapshotHandler(snapshots *snapshot.Service) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		cluster, err := getClusterFromRequest(c, "clusterID")
+		if err != nil {
+			return
+		}
+
+		snap, err := snapshots.Take(c.Request.Context(), cluster, snapshot.TriggerManual)
+		if errors.Is(err, snapshot.ErrNotSynced) || errors.Is(err, context.DeadlineExceeded) {
+			c.JSON(http.StatusServiceUnavailable, gin.H{"error": err.Error()})
+			return
+		}
+		if snap == nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "erro ao gravar snapshot"})
+			return
+		}
+		c.JSON(http.StatusCreated, snap)
+	}
+}
+
+// getSnapshotHandler retorna o grafo de um snapshot no formato do React Flow.
+// Aceita namespace, layout e format como a rota de topologia; os demais
+// parâmetros de construção respondem 400.
+func getSnapshotHandler() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		opts, ok := topologyOptionsFromQuery(c)
+		if !ok || rejectGraphBuildParams(c, "snapshots") {
+			return
+		}
+		exporter, ok := exporterFromQuery(c)
+		if !ok {
+			return
+		}
+		cluster, err := getClusterFromRequest(c, "clusterID")
+		if err != nil {
+			return
+		}
+
+		var snap models.TopologySnapshot
+		if err := db.DB.Where("id = ? AND cluster_id = ?", c.Param("snapshotID"), cluster.ID).First(&snap).Error; err != nil {
+			c.JSON(http.StatusNotFound, gin.H{"error": "snapshot não encontrado"})
+			return
+		}
+		renderSnapshot(c, &snap, opts, exporter)
+	}
+}
+
+// snapshotAtHandler atende GET /topology/:clusterID?at=<RFC3339>: o último snapshot até o instante.
+// Como em getSnapshotHandler, só namespace, layout e format se aplicam.
+func snapshotAtHandler(c *gin.Context, cluster *models.Cluster, opts k8s.TopologyOptions, exporter k8s.Exporter) {
+	if rejectGraphBuildParams(c, "consultas com at") {
+		return
+	}
+	at, err := time.Parse(time.RFC3339, c.Query("at"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "at deve estar em RFC3339"})
+		return
+	}
+
+	snap, err := snapshot.At(cluster.ID, at)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "nenhum snapshot até o instante informado"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "erro ao buscar snapshot"})
+		return
+	}
+	renderSnapshot(c, snap, opts, exporter)
+}
+
+// renderSnapshot responde com o grafo do snapshot no mesmo formato da topologia ao
+// vivo (ou no exporter, se houver); o snapshot usado vai nos headers
+// X-Snapshot-ID e X-Snapshot-Time.
+func renderSnapshot(c *gin.Context, snap *models.TopologySnapshot, opts k8s.TopologyOptions, exporter k8s.Exporter) {
+	g, err := snapshot.Load(snap)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "erro ao ler snapshot"})
+		return
+	}
+
+	c.Header("X-Snapshot-ID", strconv.FormatUint(uint64(snap.ID), 10))
+	c.Header("X-Snapshot-Time", snap.CreatedAt.UTC().Format(time.RFC3339))
+	g = k8s.FilterNamespace(g, opts.Namespace)
+	if exporter != nil {
+		writeExport(c, exporter, g, opts)
+		return
+	}
+	c.JSON(http.StatusOK, k8s.RenderGraph(g, opts.Layout))
+}
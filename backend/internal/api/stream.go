@@ -0,0 +1,200 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/example/vkube-topology/backend/internal/auth"
+	"github.com/example/vkube-topology/backend/internal/config"
+	"github.com/example/vkube-topology/backend/internal/k8s"
+)
+
+// =================================================================================
+// TOPOLOGY STREAM (SSE)
+// =================================================================================
+
+const (
+	// streamDebounce agrupa rajadas de eventos de watch (ex: rollout) em um único delta.
+	streamDebounce = time.Second
+	// streamHeartbeat mantém a conexão viva através de proxies e permite ao
+	// cliente detectar um stream travado.
+	streamHeartbeat = 15 * time.Second
+)
+
+// streamTokenHandler troca o JWT (header) por um token curto para abrir o
+// stream via EventSource, que não envia headers customizados.
+func streamTokenHandler(cfg *config.Config) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		cluster, err := getClusterFromRequest(c, "clusterID")
+		if err != nil {
+			return
+		}
+		claims := c.MustGet("user").(*auth.Claims)
+
+		token, exp, err := auth.GenerateStreamToken(claims, strconv.FormatUint(uint64(cluster.ID), 10), cfg)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "erro ao gerar token"})
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{"token": token, "expiresAt": exp})
+	}
+}
+
+// topologyStreamHandler envia a topologia por Server-Sent Events:
+//   - "status": estado do cache enquanto ele aquece
+//   - "snapshot": grafo completo (RFGraph), na conexão ou quando um delta não é possível
+//   - "delta": RFGraphDelta desde o último evento, a cada mudança vinda dos watches
+//   - "heartbeat": a cada streamHeartbeat
+//   - "close": o cache foi parado (cluster alterado/removido); o cliente deve reconectar
+//   - "error": falha ao montar o grafo
+//
+// O id de cada snapshot/delta é a revisão do grafo; ao reconectar, o EventSource
+// envia Last-Event-ID e o stream continua com um delta a partir dela. Isso só
+// funciona enquanto o token de stream vale (auth.GenerateStreamToken): depois,
+// a reconexão automática recebe 401 e o EventSource desiste. O cliente deve
+// então fechar o EventSource, pedir um novo token e abrir outro com
+// ?token=<novo>&lastEventId=<último id>, já que o EventSource não deixa
+// definir o header. "close" e "error" trazem lastEventId e resume com essa dica.
+// Aceita os mesmos parâmetros de query da rota de topologia.
+func topologyStreamHandler(cfg *config.Config, clusters *k8s.ClusterManager) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		opts, ok := topologyOptionsFromQuery(c)
+		if !ok {
+			return
+		}
+
+		clusterCache, err := getClusterCacheFromRequest(c, cfg, clusters)
+		if err != nil {
+			return
+		}
+
+		c.Header("Content-Type", "text/event-stream")
+		c.Header("Cache-Control", "no-cache")
+		c.Header("Connection", "keep-alive")
+		c.Header("X-Accel-Buffering", "no") // nginx/ingress: não bufferizar
+		c.Status(http.StatusOK)
+
+		ctx := c.Request.Context()
+		heartbeat := time.NewTicker(streamHeartbeat)
+		defer heartbeat.Stop()
+
+		// Inscreve antes do snapshot para não perder mudanças entre os dois
+		updates, unsubscribe := clusterCache.Subscribe()
+		defer unsubscribe()
+
+		// Aquecimento do cache: informa o status até sincronizar
+		for !clusterCache.Synced() {
+			writeSSE(c, "", "status", clusterCache.Status())
+			waitCtx, cancel := context.WithTimeout(ctx, cacheSyncWait)
+			clusterCache.WaitForSync(waitCtx)
+			cancel()
+			if ctx.Err() != nil {
+				return
+			}
+			select {
+			case <-clusterCache.Done():
+				writeStreamEnd(c, "close", "reason", "cache do cluster encerrado", 0)
+				return
+			default:
+			}
+		}
+
+		var since uint64
+		lastID := c.GetHeader("Last-Event-ID")
+		if lastID == "" {
+			lastID = c.Query("lastEventId")
+		}
+		if lastID != "" {
+			since, _ = strconv.ParseUint(lastID, 10, 64)
+		}
+		if since == 0 {
+			graph, err := clusterCache.BuildTopologyGraph(opts)
+			if !graphUsable(err) {
+				writeStreamEnd(c, "error", "error", "erro ao construir grafo", 0)
+				return
+			}
+			writeSSE(c, strconv.FormatUint(graph.Revision, 10), "snapshot", graph)
+			since = graph.Revision
+		} else {
+			since = sendDelta(c, clusterCache, opts, since)
+		}
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+
+			case <-clusterCache.Done():
+				writeStreamEnd(c, "close", "reason", "cache do cluster encerrado", since)
+				return
+
+			case <-heartbeat.C:
+				writeSSE(c, "", "heartbeat", gin.H{"time": time.Now().UTC()})
+
+			case <-updates:
+				// Espera a rajada terminar; sinais nesse intervalo já estão coalescidos no canal
+				select {
+				case <-ctx.Done():
+					return
+				case <-time.After(streamDebounce):
+				}
+				select {
+				case <-updates:
+				default:
+				}
+				since = sendDelta(c, clusterCache, opts, since)
+			}
+		}
+	}
+}
+
+// sendDelta envia o que mudou desde since (ou um snapshot, se a revisão
+// expirou) e retorna a revisão atual. Deltas vazios não são enviados.
+func sendDelta(c *gin.Context, clusterCache *k8s.ClusterCache, opts k8s.TopologyOptions, since uint64) uint64 {
+	delta, err := clusterCache.BuildTopologyDelta(opts, since)
+	if !graphUsable(err) {
+		writeStreamEnd(c, "error", "error", "erro ao construir grafo", since)
+		return since
+	}
+
+	id := strconv.FormatUint(delta.Revision, 10)
+	switch {
+	case delta.Full:
+		writeSSE(c, id, "snapshot", delta.Graph)
+	case delta.Revision != since:
+		writeSSE(c, id, "delta", delta)
+	}
+	return delta.Revision
+}
+
+// streamResumeHint explica ao cliente como retomar o stream com um token novo.
+const streamResumeHint = "peça um novo token em POST /topology/:clusterID/stream-token e reconecte com ?token=<token>&lastEventId=<lastEventId>"
+
+// writeStreamEnd escreve um evento "close"/"error" com a revisão para retomar
+// o stream (omitida se nenhum grafo foi enviado ainda).
+func writeStreamEnd(c *gin.Context, event, field, message string, since uint64) {
+	payload := gin.H{field: message, "resume": streamResumeHint}
+	if since != 0 {
+		payload["lastEventId"] = strconv.FormatUint(since, 10)
+	}
+	writeSSE(c, "", event, payload)
+}
+
+// writeSSE escreve um evento no formato text/event-stream e faz flush.
+func writeSSE(c *gin.Context, id, event string, payload interface{}) {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return
+	}
+	if id != "" {
+		fmt.Fprintf(c.Writer, "id: %s\n", id)
+	}
+	fmt.Fprintf(c.Writer, "event: %s\ndata: %s\n\n", event, data)
+	c.Writer.Flush()
+}
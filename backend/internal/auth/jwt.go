@@ -1,6 +1,8 @@
 package auth
 
 import (
+	"errors"
+	"slices"
 	"time"
 
 	"github.com/golang-jwt/jwt/v5"
@@ -48,3 +50,58 @@ func ParseToken(tokenStr string, cfg *config.Config) (*Claims, error) {
 	return nil, jwt.ErrTokenInvalidClaims
 }
 
+// Tokens de stream: EventSource (SSE) não permite enviar o header Authorization,
+// então o cliente troca o JWT por um token curto, restrito a um cluster, e o
+// envia na query string. O TTL curto limita o estrago caso a URL vaze em logs.
+// O exp só é conferido ao abrir a conexão: um stream aberto não cai quando o
+// token expira, mas a reconexão automática do EventSource depois disso recebe
+// 401. O cliente deve então pedir um novo token e reconectar com ?lastEventId=
+// (ver api/stream.go).
+const (
+	streamTokenAudience = "topology-stream"
+	streamTokenTTL      = time.Minute
+)
+
+// GenerateStreamToken gera um token de uso exclusivo para abrir o stream de topologia do cluster.
+func GenerateStreamToken(claims *Claims, clusterID string, cfg *config.Config) (string, time.Time, error) {
+	exp := time.Now().Add(streamTokenTTL)
+	streamClaims := &Claims{
+		Username: claims.Username,
+		Role:     claims.Role,
+		RegisteredClaims: jwt.RegisteredClaims{
+			Subject:   clusterID,
+			Audience:  jwt.ClaimStrings{streamTokenAudience},
+			ExpiresAt: jwt.NewNumericDate(exp),
+			IssuedAt:  jwt.NewNumericDate(time.Now()),
+		},
+	}
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, streamClaims)
+	signed, err := token.SignedString([]byte(cfg.JWTSecret))
+	if err != nil {
+		return "", time.Time{}, err
+	}
+	return signed, exp, nil
+}
+
+// ParseStreamToken valida um token de stream e confere se ele foi emitido para o cluster.
+func ParseStreamToken(tokenStr, clusterID string, cfg *config.Config) (*Claims, error) {
+	token, err := jwt.ParseWithClaims(tokenStr, &Claims{}, func(token *jwt.Token) (interface{}, error) {
+		return []byte(cfg.JWTSecret), nil
+	}, jwt.WithAudience(streamTokenAudience))
+	if err != nil {
+		return nil, err
+	}
+	claims, ok := token.Claims.(*Claims)
+	if !ok || !token.Valid {
+		return nil, jwt.ErrTokenInvalidClaims
+	}
+	if claims.Subject != clusterID {
+		return nil, errors.New("token de stream emitido para outro cluster")
+	}
+	return claims, nil
+}
+
+// isStreamToken indica se os claims são de um token de stream, que não vale para o restante da API.
+func isStreamToken(claims *Claims) bool {
+	return slices.Contains(claims.Audience, streamTokenAudience)
+}
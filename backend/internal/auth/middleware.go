@@ -23,7 +23,7 @@ func AuthMiddleware(cfg *config.Config) gin.HandlerFunc {
 			return
 		}
 		claims, err := ParseToken(parts[1], cfg)
-		if err != nil {
+		if err != nil || isStreamToken(claims) {
 			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "token inválido"})
 			return
 		}
@@ -32,6 +32,26 @@ func AuthMiddleware(cfg *config.Config) gin.HandlerFunc {
 	}
 }
 
+// StreamAuthMiddleware aceita o header Authorization normal ou, para clientes
+// EventSource, um token de stream em ?token= emitido para o :clusterID da rota.
+func StreamAuthMiddleware(cfg *config.Config) gin.HandlerFunc {
+	headerAuth := AuthMiddleware(cfg)
+	return func(c *gin.Context) {
+		tokenStr := c.Query("token")
+		if tokenStr == "" {
+			headerAuth(c)
+			return
+		}
+		claims, err := ParseStreamToken(tokenStr, c.Param("clusterID"), cfg)
+		if err != nil {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "token de stream inválido"})
+			return
+		}
+		c.Set("user", claims)
+		c.Next()
+	}
+}
+
 // RequireRole garante que o usuário possui um dos papéis esperados.
 func RequireRole(allowed ...string) gin.HandlerFunc {
 	allowedSet := map[string]struct{}{}
@@ -25,6 +25,15 @@ type Config struct {
 	LDAPBindPass   string
 	PollInterval   time.Duration
 	MaxClusters    int
+	CacheIdleTTL   time.Duration
+
+	// Snapshots de topologia (tirados a cada PollInterval)
+	SnapshotsEnabled      bool
+	SnapshotRetention     time.Duration
+	SnapshotMaxPerCluster int
+
+	// Arquivo de regras de arestas declaradas (YAML/JSON); vazio = só anotações
+	EdgeRulesFile string
 }
 
 // LoadEnv tenta carregar variáveis de ambiente de um arquivo .env (modo dev).
@@ -53,6 +62,13 @@ func New() *Config {
 		LDAPBindPass:  getEnv("LDAP_BIND_PASSWORD", "admin"),
 		PollInterval:  time.Duration(getEnvInt("POLL_INTERVAL_SECONDS", 15)) * time.Second,
 		MaxClusters:   getEnvInt("MAX_CLUSTERS_PER_USER", 20),
+		CacheIdleTTL:  time.Duration(getEnvInt("TOPOLOGY_CACHE_IDLE_MINUTES", 10)) * time.Minute,
+
+		SnapshotsEnabled:      getEnv("SNAPSHOT_ENABLED", "true") == "true",
+		SnapshotRetention:     time.Duration(getEnvInt("SNAPSHOT_RETENTION_HOURS", 168)) * time.Hour,
+		SnapshotMaxPerCluster: getEnvInt("SNAPSHOT_MAX_PER_CLUSTER", 2000),
+
+		EdgeRulesFile: getEnv("EDGE_RULES_FILE", ""),
 	}
 }
 
@@ -37,6 +37,7 @@ func AutoMigrate() error {
 	return DB.AutoMigrate(
 		&models.User{},
 		&models.Cluster{},
+		&models.TopologySnapshot{},
 	)
 }
 
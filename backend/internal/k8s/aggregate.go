@@ -0,0 +1,258 @@
+package k8s
+
+import (
+	"fmt"
+
+	appsv1 "k8s.io/api/apps/v1"
+)
+
+/*
+========================
+ NÍVEL DE DETALHE (AGREGAÇÃO DE REPLICASETS E PODS)
+========================
+*/
+
+// Níveis de detalhe via parâmetro detail= da rota de topologia.
+const (
+	DetailWorkload   = "workload"   // Pods e ReplicaSets de Deployments viram contagens no workload
+	DetailReplicaSet = "replicaset" // Pods viram contagens no dono
+	DetailPod        = "pod"        // grafo completo (padrão)
+)
+
+// collapsedKinds são os kinds absorvidos pelo dono em cada nível.
+var collapsedKinds = map[string]map[string]bool{
+	DetailWorkload:   {"Pod": true, "ReplicaSet": true},
+	DetailReplicaSet: {"Pod": true},
+}
+
+// ValidateDetail verifica o nível de detalhe recebido pela API.
+func ValidateDetail(detail string) error {
+	switch detail {
+	case "", DetailWorkload, DetailReplicaSet, DetailPod:
+		return nil
+	}
+	return fmt.Errorf("detail deve ser workload, replicaset ou pod")
+}
+
+// NextDetail é o nível usado para expandir um nó agregado em detail.
+func NextDetail(detail string) string {
+	if detail == DetailWorkload {
+		return DetailReplicaSet
+	}
+	return DetailPod
+}
+
+// historicalReplicaSet indica um ReplicaSet antigo de Deployment, mantido só
+// para rollback: sem réplicas desejadas nem existentes.
+func historicalReplicaSet(rs *appsv1.ReplicaSet) bool {
+	if desiredReplicas(rs.Spec.Replicas) != 0 || rs.Status.Replicas != 0 {
+		return false
+	}
+	for _, ref := range rs.OwnerReferences {
+		if ref.Kind == "Deployment" {
+			return true
+		}
+	}
+	return false
+}
+
+// hideHistoricalReplicaSets remove os ReplicaSets históricos antes da
+// construção do grafo (fora do nível pod, a menos que ShowHistory).
+func hideHistoricalReplicaSets(res *clusterResources, opts TopologyOptions) {
+	if opts.ShowHistory || opts.Detail == "" || opts.Detail == DetailPod {
+		return
+	}
+	kept := res.ReplicaSets[:0:0]
+	for _, rs := range res.ReplicaSets {
+		if !historicalReplicaSet(rs) {
+			kept = append(kept, rs)
+		}
+	}
+	res.ReplicaSets = kept
+}
+
+// aggregateGraph absorve os nós dos kinds agregados no nível em seus donos
+// (arestas owns): o dono recebe data.collapsed (contagem por kind),
+// data.podPhases e data.expandable. As arestas dos nós absorvidos passam para o
+// dono, deduplicadas por tipo, com data.count (e data.readyCount em selects);
+// uma aresta direta do mesmo tipo entre os mesmos nós entra na contagem.
+// Pods e ReplicaSets sem dono continuam no grafo.
+func aggregateGraph(g *ClusterGraph, detail string) {
+	collapse := collapsedKinds[detail]
+	if collapse == nil {
+		return
+	}
+
+	index := make(map[string]int, len(g.Nodes))
+	for i, n := range g.Nodes {
+		index[n.ID] = i
+	}
+	owner := map[string]string{}
+	for _, e := range g.Edges {
+		if e.Type == EdgeOwns {
+			owner[e.Target] = e.Source
+		}
+	}
+
+	// Representante de cada nó: sobe pelos donos enquanto o kind for agregado
+	rep := make(map[string]string, len(g.Nodes))
+	var resolve func(id string) string
+	resolve = func(id string) string {
+		if r, ok := rep[id]; ok {
+			return r
+		}
+		r := id
+		if i, ok := index[id]; ok && collapse[g.Nodes[i].Kind] {
+			if o, ok := owner[id]; ok {
+				r = resolve(o)
+			}
+		}
+		rep[id] = r
+		return r
+	}
+
+	nodes := make([]GraphNode, 0, len(g.Nodes))
+	for _, n := range g.Nodes {
+		r := resolve(n.ID)
+		if r == n.ID {
+			nodes = append(nodes, n)
+			continue
+		}
+		target := &g.Nodes[index[r]]
+		data := map[string]interface{}{}
+		for k, v := range target.Data {
+			data[k] = v
+		}
+		counts, _ := data["collapsed"].(map[string]int)
+		if counts == nil {
+			counts = map[string]int{}
+		} else {
+			counts = copyCounts(counts)
+		}
+		counts[n.Kind]++
+		data["collapsed"] = counts
+		if n.Kind == "Pod" {
+			phases, _ := data["podPhases"].(map[string]int)
+			phases = copyCounts(phases)
+			phase, _ := n.Data["phase"].(string)
+			if phase == "" {
+				phase = "Unknown"
+			}
+			phases[phase]++
+			data["podPhases"] = phases
+		}
+		data["expandable"] = true
+		target.Data = data
+	}
+	// Os nós mantidos são cópias anteriores ao acúmulo: relê os dados finais
+	for i := range nodes {
+		nodes[i].Data = g.Nodes[index[nodes[i].ID]].Data
+	}
+
+	edges := make([]GraphEdge, 0, len(g.Edges))
+	byKey := map[string]int{}
+	for _, e := range g.Edges {
+		s, t := resolve(e.Source), resolve(e.Target)
+		if s == t {
+			continue // owns interno ao dono agregado
+		}
+		key := e.Type + "|" + s + "|" + t
+		collapsed := s != e.Source || t != e.Target
+		if i, ok := byKey[key]; ok {
+			agg, _ := edges[i].Data["aggregated"].(bool)
+			if !agg && !collapsed {
+				continue
+			}
+			if !agg {
+				// Aresta direta já presente: vira a agregada e conta a si mesma
+				edges[i].Data = aggregatedEdgeData(edges[i].Data)
+			}
+			addToAggregatedEdge(edges[i].Data, e)
+			continue
+		}
+		if collapsed {
+			data := map[string]interface{}{"aggregated": true, "count": 0, "readyCount": 0}
+			addToAggregatedEdge(data, e)
+			e = GraphEdge{ID: "edge:agg:" + e.Type + ":" + s + "->" + t, Source: s, Target: t, Type: e.Type, Data: data}
+		}
+		byKey[key] = len(edges)
+		edges = append(edges, e)
+	}
+
+	g.Nodes = nodes
+	g.Edges = edges
+}
+
+// aggregatedEdgeData converte os dados de uma aresta direta nos de uma
+// aresta agregada que já conta a própria aresta.
+func aggregatedEdgeData(d map[string]interface{}) map[string]interface{} {
+	data := make(map[string]interface{}, len(d)+3)
+	for k, v := range d {
+		data[k] = v
+	}
+	data["aggregated"], data["count"], data["readyCount"] = true, 1, 0
+	if ready, _ := d["ready"].(bool); ready {
+		data["readyCount"] = 1
+	}
+	return data
+}
+
+// addToAggregatedEdge soma a aresta e em data.count (e data.readyCount).
+func addToAggregatedEdge(data map[string]interface{}, e GraphEdge) {
+	count, _ := data["count"].(int)
+	data["count"] = count + 1
+	if ready, _ := e.Data["ready"].(bool); ready {
+		readyCount, _ := data["readyCount"].(int)
+		data["readyCount"] = readyCount + 1
+	}
+}
+
+func copyCounts(m map[string]int) map[string]int {
+	out := make(map[string]int, len(m)+1)
+	for k, v := range m {
+		out[k] = v
+	}
+	return out
+}
+
+// ExpandNode recorta de g (construído num nível abaixo do da visão, ver
+// NextDetail) o nó id e seus filhos diretos pelas arestas owns, com as arestas
+// dos filhos para o próprio nó, entre si e para o que a visão em viewDetail já
+// mostra (Services, Nodes, PVCs...). Retorna ErrNotFound se id não existe em g.
+func ExpandNode(g *ClusterGraph, id, viewDetail string) (*ClusterGraph, error) {
+	if !hasNode(g, id) {
+		return nil, fmt.Errorf("nó %s: %w", id, ErrNotFound)
+	}
+
+	keep := map[string]bool{id: true}
+	for _, e := range g.Edges {
+		if e.Type == EdgeOwns && e.Source == id {
+			keep[e.Target] = true
+		}
+	}
+
+	hidden := collapsedKinds[viewDetail]
+	kinds := make(map[string]string, len(g.Nodes))
+	for _, n := range g.Nodes {
+		kinds[n.ID] = n.Kind
+	}
+
+	out := &ClusterGraph{Nodes: []GraphNode{}, Edges: []GraphEdge{}, Warnings: g.Warnings}
+	for _, n := range g.Nodes {
+		if keep[n.ID] {
+			n.Parent = "" // o grupo já está na visão do cliente
+			out.Nodes = append(out.Nodes, n)
+		}
+	}
+	for _, e := range g.Edges {
+		if (e.Source == id || !keep[e.Source]) && (e.Target == id || !keep[e.Target]) {
+			continue // só arestas que tocam algum filho
+		}
+		// A outra ponta precisa existir na visão (ou no próprio recorte)
+		if (!keep[e.Source] && hidden[kinds[e.Source]]) || (!keep[e.Target] && hidden[kinds[e.Target]]) {
+			continue
+		}
+		out.Edges = append(out.Edges, e)
+	}
+	return out, nil
+}
@@ -0,0 +1,69 @@
+package k8s
+
+import "testing"
+
+func TestAggregateGraphMergesDirectEdge(t *testing.T) {
+	tests := []struct {
+		name       string
+		edges      []GraphEdge
+		count      int
+		readyCount int
+	}{
+		{
+			name: "aresta direta antes das absorvidas",
+			edges: []GraphEdge{
+				{ID: "direct", Source: "svc:shop:web", Target: "deploy:shop:web", Type: EdgeSelects, Data: map[string]interface{}{"ready": true}},
+				{ID: "s1", Source: "svc:shop:web", Target: "pod:shop:web-1", Type: EdgeSelects, Data: map[string]interface{}{"ready": true}},
+				{ID: "s2", Source: "svc:shop:web", Target: "pod:shop:web-2", Type: EdgeSelects, Data: map[string]interface{}{"ready": false}},
+			},
+			count:      3,
+			readyCount: 2,
+		},
+		{
+			name: "aresta direta depois das absorvidas",
+			edges: []GraphEdge{
+				{ID: "s1", Source: "svc:shop:web", Target: "pod:shop:web-1", Type: EdgeSelects, Data: map[string]interface{}{"ready": true}},
+				{ID: "direct", Source: "svc:shop:web", Target: "deploy:shop:web", Type: EdgeSelects},
+			},
+			count:      2,
+			readyCount: 1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := &ClusterGraph{
+				Nodes: []GraphNode{
+					{ID: "deploy:shop:web", Kind: "Deployment"},
+					{ID: "rs:shop:web-1", Kind: "ReplicaSet"},
+					{ID: "pod:shop:web-1", Kind: "Pod"},
+					{ID: "pod:shop:web-2", Kind: "Pod"},
+					{ID: "svc:shop:web", Kind: "Service"},
+				},
+				Edges: append([]GraphEdge{
+					{ID: "o1", Source: "deploy:shop:web", Target: "rs:shop:web-1", Type: EdgeOwns},
+					{ID: "o2", Source: "rs:shop:web-1", Target: "pod:shop:web-1", Type: EdgeOwns},
+					{ID: "o3", Source: "rs:shop:web-1", Target: "pod:shop:web-2", Type: EdgeOwns},
+				}, tt.edges...),
+			}
+
+			aggregateGraph(g, DetailWorkload)
+
+			var selects []GraphEdge
+			for _, e := range g.Edges {
+				if e.Type == EdgeSelects {
+					selects = append(selects, e)
+				}
+			}
+			if len(selects) != 1 {
+				t.Fatalf("arestas selects = %+v, want 1", selects)
+			}
+			if got := selects[0].Data["count"]; got != tt.count {
+				t.Errorf("count = %v, want %d", got, tt.count)
+			}
+			if got := selects[0].Data["readyCount"]; got != tt.readyCount {
+				t.Errorf("readyCount = %v, want %d", got, tt.readyCount)
+			}
+		})
+	}
+}
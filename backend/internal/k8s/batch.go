@@ -0,0 +1,88 @@
+package k8s
+
+import (
+	"time"
+
+	batchv1 "k8s.io/api/batch/v1"
+)
+
+/*
+========================
+ JOBS E CRONJOBS
+========================
+*/
+
+// addBatchGraph adiciona Jobs e CronJobs com as arestas de posse CronJob -> Job -> Pod.
+func addBatchGraph(g *ClusterGraph, res *clusterResources) {
+	for _, cj := range res.CronJobs {
+		data := map[string]interface{}{
+			"schedule": cj.Spec.Schedule,
+			"suspend":  cj.Spec.Suspend != nil && *cj.Spec.Suspend,
+			"active":   len(cj.Status.Active),
+		}
+		if t := cj.Status.LastScheduleTime; t != nil {
+			data["lastScheduleTime"] = t.UTC().Format(time.RFC3339)
+		}
+		if t := cj.Status.LastSuccessfulTime; t != nil {
+			data["lastSuccessfulTime"] = t.UTC().Format(time.RFC3339)
+		}
+		g.Nodes = append(g.Nodes, GraphNode{
+			ID: "cronjob:" + cj.Namespace + ":" + cj.Name, Kind: "CronJob", Name: cj.Name, Namespace: cj.Namespace, Labels: cj.Labels,
+			Data: data,
+		})
+	}
+
+	for _, job := range res.Jobs {
+		jobID := "job:" + job.Namespace + ":" + job.Name
+		g.Nodes = append(g.Nodes, GraphNode{
+			ID: jobID, Kind: "Job", Name: job.Name, Namespace: job.Namespace, Labels: job.Labels,
+			Data: jobData(job),
+		})
+
+		if ref := controllerRef(job.OwnerReferences); ref != nil && ref.Kind == "CronJob" {
+			g.Edges = append(g.Edges, GraphEdge{
+				ID:     "edge:cronjob->job:" + job.Namespace + ":" + ref.Name + "->" + job.Name,
+				Source: "cronjob:" + job.Namespace + ":" + ref.Name,
+				Target: jobID,
+				Type:   EdgeOwns,
+			})
+		}
+	}
+
+	for _, pod := range res.Pods {
+		if ref := controllerRef(pod.OwnerReferences); ref != nil && ref.Kind == "Job" {
+			g.Edges = append(g.Edges, GraphEdge{
+				ID:     "edge:job->pod:" + pod.Namespace + ":" + ref.Name + "->" + pod.Name,
+				Source: "job:" + pod.Namespace + ":" + ref.Name,
+				Target: "pod:" + pod.Namespace + ":" + pod.Name,
+				Type:   EdgeOwns,
+			})
+		}
+	}
+}
+
+func jobData(job *batchv1.Job) map[string]interface{} {
+	data := map[string]interface{}{
+		"active":    job.Status.Active,
+		"succeeded": job.Status.Succeeded,
+		"failed":    job.Status.Failed,
+	}
+	if job.Spec.Completions != nil {
+		data["completions"] = *job.Spec.Completions
+	}
+	if job.Spec.Parallelism != nil {
+		data["parallelism"] = *job.Spec.Parallelism
+	}
+	if t := job.Status.StartTime; t != nil {
+		data["startTime"] = t.UTC().Format(time.RFC3339)
+	}
+	if t := job.Status.CompletionTime; t != nil {
+		data["completionTime"] = t.UTC().Format(time.RFC3339)
+	}
+	for _, c := range job.Status.Conditions {
+		if (c.Type == batchv1.JobComplete || c.Type == batchv1.JobFailed) && c.Status == "True" {
+			data["condition"] = string(c.Type)
+		}
+	}
+	return data
+}
@@ -3,11 +3,19 @@ package k8s
 import (
 	"fmt"
 
+	"k8s.io/client-go/dynamic"
 	"k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/rest"
 	"k8s.io/client-go/tools/clientcmd"
 )
 
+// Clients agrupa os clients usados na leitura da topologia. O dynamic client é
+// usado para recursos sem tipos no client-go (ex: Gateway API).
+type Clients struct {
+	Kube    kubernetes.Interface
+	Dynamic dynamic.Interface
+}
+
 // NewClient cria um clientset a partir de um kubeconfig em bytes.
 func NewClient(kubeconfig []byte) (*kubernetes.Clientset, error) {
 	config, err := buildConfigFromBytes(kubeconfig)
@@ -18,6 +26,25 @@ func NewClient(kubeconfig []byte) (*kubernetes.Clientset, error) {
 	return kubernetes.NewForConfig(config)
 }
 
+// NewClients cria o clientset e o dynamic client a partir de um kubeconfig em bytes.
+func NewClients(kubeconfig []byte) (*Clients, error) {
+	config, err := buildConfigFromBytes(kubeconfig)
+	if err != nil {
+		return nil, err
+	}
+
+	kube, err := kubernetes.NewForConfig(config)
+	if err != nil {
+		return nil, err
+	}
+	dyn, err := dynamic.NewForConfig(config)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Clients{Kube: kube, Dynamic: dyn}, nil
+}
+
 func buildConfigFromBytes(kubeconfig []byte) (*rest.Config, error) {
 	cfg, err := clientcmd.RESTConfigFromKubeConfig(kubeconfig)
 	if err != nil {
@@ -0,0 +1,221 @@
+package k8s
+
+import (
+	"fmt"
+	"sort"
+
+	corev1 "k8s.io/api/core/v1"
+)
+
+/*
+========================
+ CONFIGMAP / SECRET (DEPENDÊNCIAS DE CONFIGURAÇÃO)
+========================
+*/
+
+// Granularidade das arestas de configuração (TopologyOptions.ConfigEdges).
+const (
+	ConfigEdgesWorkload = "workload" // colapsa no Deployment/StatefulSet/DaemonSet dono do pod (padrão)
+	ConfigEdgesPod      = "pod"      // uma aresta por pod
+)
+
+// ValidateConfigEdges verifica a granularidade recebida pela API.
+func ValidateConfigEdges(mode string) error {
+	switch mode {
+	case "", ConfigEdgesWorkload, ConfigEdgesPod:
+		return nil
+	}
+	return fmt.Errorf("configEdges deve ser workload ou pod")
+}
+
+// Origem de uma referência a ConfigMap/Secret dentro do pod spec.
+const (
+	refViaVolume          = "volume"
+	refViaProjected       = "projected"
+	refViaEnvFrom         = "envFrom"
+	refViaEnv             = "env"
+	refViaImagePullSecret = "imagePullSecret"
+)
+
+// configRef é uma referência de um pod a um ConfigMap ou Secret.
+type configRef struct {
+	kind string // ConfigMap | Secret
+	name string
+	via  string
+	key  string // só para env.valueFrom
+}
+
+// podConfigRefs extrai todas as referências a ConfigMaps e Secrets de um pod.
+func podConfigRefs(spec *corev1.PodSpec) []configRef {
+	refs := []configRef{}
+
+	for _, vol := range spec.Volumes {
+		if vol.ConfigMap != nil {
+			refs = append(refs, configRef{kind: "ConfigMap", name: vol.ConfigMap.Name, via: refViaVolume})
+		}
+		if vol.Secret != nil {
+			refs = append(refs, configRef{kind: "Secret", name: vol.Secret.SecretName, via: refViaVolume})
+		}
+		if vol.Projected != nil {
+			for _, src := range vol.Projected.Sources {
+				if src.ConfigMap != nil {
+					refs = append(refs, configRef{kind: "ConfigMap", name: src.ConfigMap.Name, via: refViaProjected})
+				}
+				if src.Secret != nil {
+					refs = append(refs, configRef{kind: "Secret", name: src.Secret.Name, via: refViaProjected})
+				}
+			}
+		}
+	}
+
+	addContainer := func(envFrom []corev1.EnvFromSource, env []corev1.EnvVar) {
+		for _, ef := range envFrom {
+			if ef.ConfigMapRef != nil {
+				refs = append(refs, configRef{kind: "ConfigMap", name: ef.ConfigMapRef.Name, via: refViaEnvFrom})
+			}
+			if ef.SecretRef != nil {
+				refs = append(refs, configRef{kind: "Secret", name: ef.SecretRef.Name, via: refViaEnvFrom})
+			}
+		}
+		for _, e := range env {
+			if e.ValueFrom == nil {
+				continue
+			}
+			if r := e.ValueFrom.ConfigMapKeyRef; r != nil {
+				refs = append(refs, configRef{kind: "ConfigMap", name: r.Name, via: refViaEnv, key: r.Key})
+			}
+			if r := e.ValueFrom.SecretKeyRef; r != nil {
+				refs = append(refs, configRef{kind: "Secret", name: r.Name, via: refViaEnv, key: r.Key})
+			}
+		}
+	}
+	for _, c := range spec.InitContainers {
+		addContainer(c.EnvFrom, c.Env)
+	}
+	for _, c := range spec.Containers {
+		addContainer(c.EnvFrom, c.Env)
+	}
+	for _, c := range spec.EphemeralContainers {
+		addContainer(c.EnvFrom, c.Env)
+	}
+
+	for _, s := range spec.ImagePullSecrets {
+		refs = append(refs, configRef{kind: "Secret", name: s.Name, via: refViaImagePullSecret})
+	}
+
+	return refs
+}
+
+// addConfigGraph adiciona ConfigMaps/Secrets referenciados pelos pods e as arestas
+// de dependência. Só entram os objetos referenciados: os demais (kube-root-ca.crt,
+// releases do Helm, tokens...) não afetam nenhum workload e só poluiriam o grafo.
+// Referências a objetos inexistentes viram nós com missing=true.
+func addConfigGraph(g *ClusterGraph, res *clusterResources, mode string) {
+	configMaps := make(map[string]*corev1.ConfigMap, len(res.ConfigMaps))
+	for _, cm := range res.ConfigMaps {
+		configMaps[cm.Namespace+"/"+cm.Name] = cm
+	}
+	secrets := make(map[string]*corev1.Secret, len(res.Secrets))
+	for _, s := range res.Secrets {
+		secrets[s.Namespace+"/"+s.Name] = s
+	}
+	owners := newOwnerIndex(res)
+
+	type edgeAgg struct {
+		source, target string
+		via            map[string]bool
+		keys           map[string]bool
+	}
+	edges := map[string]*edgeAgg{}
+	edgeOrder := []string{}
+	targets := map[string]configRef{} // node ID -> referência (para criar o nó)
+	targetNs := map[string]string{}
+
+	for _, pod := range res.Pods {
+		source := "pod:" + pod.Namespace + ":" + pod.Name
+		if mode != ConfigEdgesPod {
+			if id, ok := owners.workloadID(pod); ok {
+				source = id
+			}
+		}
+
+		for _, ref := range podConfigRefs(&pod.Spec) {
+			if ref.name == "" {
+				continue
+			}
+			prefix := "cm:"
+			if ref.kind == "Secret" {
+				prefix = "secret:"
+			}
+			target := prefix + pod.Namespace + ":" + ref.name
+			targets[target] = ref
+			targetNs[target] = pod.Namespace
+
+			id := "edge:" + source + "->" + target
+			agg, ok := edges[id]
+			if !ok {
+				agg = &edgeAgg{source: source, target: target, via: map[string]bool{}, keys: map[string]bool{}}
+				edges[id] = agg
+				edgeOrder = append(edgeOrder, id)
+			}
+			agg.via[ref.via] = true
+			if ref.key != "" {
+				agg.keys[ref.key] = true
+			}
+		}
+	}
+
+	targetIDs := make([]string, 0, len(targets))
+	for id := range targets {
+		targetIDs = append(targetIDs, id)
+	}
+	sort.Strings(targetIDs)
+	for _, id := range targetIDs {
+		ref, ns := targets[id], targetNs[id]
+		node := GraphNode{ID: id, Kind: ref.kind, Name: ref.name, Namespace: ns}
+		switch ref.kind {
+		case "ConfigMap":
+			if cm, ok := configMaps[ns+"/"+ref.name]; ok {
+				keys := sortedKeys(cm.Data)
+				keys = append(keys, sortedKeys(cm.BinaryData)...)
+				node.Labels = cm.Labels
+				node.Data = map[string]interface{}{"keys": keys, "immutable": cm.Immutable != nil && *cm.Immutable}
+			} else {
+				node.Data = map[string]interface{}{"missing": true}
+			}
+		case "Secret":
+			// Nunca expor valores: apenas tipo e nomes das chaves
+			if s, ok := secrets[ns+"/"+ref.name]; ok {
+				node.Labels = s.Labels
+				node.Data = map[string]interface{}{"type": string(s.Type), "keys": sortedKeys(s.Data)}
+			} else {
+				node.Data = map[string]interface{}{"missing": true}
+			}
+		}
+		g.Nodes = append(g.Nodes, node)
+	}
+
+	for _, id := range edgeOrder {
+		agg := edges[id]
+		data := map[string]interface{}{"via": sortedKeys(agg.via)}
+		if len(agg.keys) > 0 {
+			data["keys"] = sortedKeys(agg.keys)
+		}
+		g.Edges = append(g.Edges, GraphEdge{
+			ID:     id,
+			Source: agg.source,
+			Target: agg.target,
+			Type:   EdgeUsesConfig,
+			Data:   data,
+		})
+	}
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,321 @@
+package k8s
+
+import (
+	"encoding/json"
+	"reflect"
+	"strconv"
+)
+
+/*
+========================
+ DIFF ENTRE TOPOLOGIAS
+========================
+*/
+
+// Status de um nó/aresta no diff (data.diff no overlay).
+const (
+	DiffAdded     = "added"
+	DiffRemoved   = "removed"
+	DiffChanged   = "changed"
+	DiffUnchanged = "unchanged"
+)
+
+// FieldChange é a mudança de um campo: "labels.<chave>", "data.<chave>" ou "parent".
+type FieldChange struct {
+	Field  string      `json:"field"`
+	Before interface{} `json:"before,omitempty"`
+	After  interface{} `json:"after,omitempty"`
+}
+
+type NodeDiff struct {
+	ID        string        `json:"id"` // ID no grafo "depois" (ou "antes", se removido)
+	Kind      string        `json:"kind"`
+	Name      string        `json:"name"`
+	Namespace string        `json:"namespace,omitempty"`
+	Status    string        `json:"status"`
+	Changes   []FieldChange `json:"changes,omitempty"`
+}
+
+type EdgeDiff struct {
+	ID      string        `json:"id"`
+	Source  string        `json:"source"`
+	Target  string        `json:"target"`
+	Type    string        `json:"type"`
+	Status  string        `json:"status"`
+	Changes []FieldChange `json:"changes,omitempty"`
+}
+
+// GraphDiff lista apenas o que mudou; Summary conta também os inalterados.
+type GraphDiff struct {
+	Summary map[string]map[string]int `json:"summary"` // nodes|edges -> status -> quantidade
+	Nodes   []NodeDiff                `json:"nodes"`
+	Edges   []EdgeDiff                `json:"edges"`
+}
+
+// DiffOptions controla como nós de grafos diferentes são pareados.
+type DiffOptions struct {
+	// IgnoreNamespace pareia nós namespaced por kind/nome, para comparar dois
+	// namespaces (ex: staging x production). Por padrão o pareamento é por ID.
+	IgnoreNamespace bool
+}
+
+// graphIndex guarda chaves de pareamento e os nós/arestas por chave.
+type graphIndex struct {
+	nodeKeys  map[string]string // ID -> chave
+	nodes     map[string]*GraphNode
+	nodeOrder []string
+	edges     map[string]*GraphEdge
+	edgeOrder []string
+}
+
+func indexGraph(g *ClusterGraph, opts DiffOptions) *graphIndex {
+	idx := &graphIndex{
+		nodeKeys: make(map[string]string, len(g.Nodes)),
+		nodes:    make(map[string]*GraphNode, len(g.Nodes)),
+		edges:    make(map[string]*GraphEdge, len(g.Edges)),
+	}
+	for i := range g.Nodes {
+		n := &g.Nodes[i]
+		key := n.ID
+		if opts.IgnoreNamespace {
+			switch {
+			case n.Kind == "Namespace":
+				key = "Namespace"
+			case n.Namespace != "":
+				key = n.Kind + "/" + n.Name
+			}
+		}
+		idx.nodeKeys[n.ID] = key
+		idx.nodes[key] = n
+		idx.nodeOrder = append(idx.nodeOrder, key)
+	}
+
+	seen := map[string]int{}
+	for i := range g.Edges {
+		e := &g.Edges[i]
+		key := e.ID
+		if opts.IgnoreNamespace {
+			// Arestas repetidas entre os mesmos nós (ex: uma por path do Ingress) são numeradas
+			base := e.Type + "|" + idx.nodeKeys[e.Source] + "|" + idx.nodeKeys[e.Target]
+			key = base + "|" + strconv.Itoa(seen[base])
+			seen[base]++
+		}
+		idx.edges[key] = e
+		idx.edgeOrder = append(idx.edgeOrder, key)
+	}
+	return idx
+}
+
+// normalizeGraph passa o grafo por JSON para que dados vindos de snapshots
+// (números como float64) e do cache (int, int32...) sejam comparáveis.
+func normalizeGraph(g *ClusterGraph) *ClusterGraph {
+	raw, err := json.Marshal(g)
+	if err != nil {
+		return g
+	}
+	var out ClusterGraph
+	if err := json.Unmarshal(raw, &out); err != nil {
+		return g
+	}
+	return &out
+}
+
+// DiffGraphs compara before e after nó a nó e aresta a aresta.
+func DiffGraphs(before, after *ClusterGraph, opts DiffOptions) *GraphDiff {
+	before, after = normalizeGraph(before), normalizeGraph(after)
+	bi, ai := indexGraph(before, opts), indexGraph(after, opts)
+
+	d := &GraphDiff{
+		Summary: map[string]map[string]int{"nodes": {}, "edges": {}},
+		Nodes:   []NodeDiff{},
+		Edges:   []EdgeDiff{},
+	}
+
+	for _, key := range ai.nodeOrder {
+		n := ai.nodes[key]
+		nd := NodeDiff{ID: n.ID, Kind: n.Kind, Name: n.Name, Namespace: n.Namespace, Status: DiffAdded}
+		if old, ok := bi.nodes[key]; ok {
+			nd.Changes = nodeChanges(old, n, bi, ai)
+			nd.Status = DiffUnchanged
+			if len(nd.Changes) > 0 {
+				nd.Status = DiffChanged
+			}
+		}
+		d.Summary["nodes"][nd.Status]++
+		if nd.Status != DiffUnchanged {
+			d.Nodes = append(d.Nodes, nd)
+		}
+	}
+	for _, key := range bi.nodeOrder {
+		if _, ok := ai.nodes[key]; !ok {
+			n := bi.nodes[key]
+			d.Nodes = append(d.Nodes, NodeDiff{ID: n.ID, Kind: n.Kind, Name: n.Name, Namespace: n.Namespace, Status: DiffRemoved})
+			d.Summary["nodes"][DiffRemoved]++
+		}
+	}
+
+	for _, key := range ai.edgeOrder {
+		e := ai.edges[key]
+		ed := EdgeDiff{ID: e.ID, Source: e.Source, Target: e.Target, Type: e.Type, Status: DiffAdded}
+		if old, ok := bi.edges[key]; ok {
+			ed.Changes = mapChanges("data.", old.Data, e.Data)
+			if old.Label != e.Label {
+				ed.Changes = append(ed.Changes, FieldChange{Field: "label", Before: old.Label, After: e.Label})
+			}
+			ed.Status = DiffUnchanged
+			if len(ed.Changes) > 0 {
+				ed.Status = DiffChanged
+			}
+		}
+		d.Summary["edges"][ed.Status]++
+		if ed.Status != DiffUnchanged {
+			d.Edges = append(d.Edges, ed)
+		}
+	}
+	for _, key := range bi.edgeOrder {
+		if _, ok := ai.edges[key]; !ok {
+			e := bi.edges[key]
+			d.Edges = append(d.Edges, EdgeDiff{ID: e.ID, Source: e.Source, Target: e.Target, Type: e.Type, Status: DiffRemoved})
+			d.Summary["edges"][DiffRemoved]++
+		}
+	}
+	return d
+}
+
+func nodeChanges(before, after *GraphNode, bi, ai *graphIndex) []FieldChange {
+	changes := []FieldChange{}
+	if bi.nodeKeys[before.Parent] != ai.nodeKeys[after.Parent] {
+		changes = append(changes, FieldChange{Field: "parent", Before: before.Parent, After: after.Parent})
+	}
+
+	bl := make(map[string]interface{}, len(before.Labels))
+	for k, v := range before.Labels {
+		bl[k] = v
+	}
+	al := make(map[string]interface{}, len(after.Labels))
+	for k, v := range after.Labels {
+		al[k] = v
+	}
+	changes = append(changes, mapChanges("labels.", bl, al)...)
+	changes = append(changes, mapChanges("data.", before.Data, after.Data)...)
+	return changes
+}
+
+// mapChanges compara dois mapas chave a chave, em ordem alfabética.
+func mapChanges(prefix string, before, after map[string]interface{}) []FieldChange {
+	keys := map[string]bool{}
+	for k := range before {
+		keys[k] = true
+	}
+	for k := range after {
+		keys[k] = true
+	}
+
+	changes := []FieldChange{}
+	for _, k := range sortedKeys(keys) {
+		b, inBefore := before[k]
+		a, inAfter := after[k]
+		if inBefore && inAfter && reflect.DeepEqual(a, b) {
+			continue
+		}
+		changes = append(changes, FieldChange{Field: prefix + k, Before: b, After: a})
+	}
+	return changes
+}
+
+// DiffOverlay monta um grafo único (after + removidos de before) para exibir
+// o diff no React Flow: cada nó/aresta recebe data.diff com o status e, se
+// alterado, data.diffChanges com as mudanças de campo.
+func DiffOverlay(before, after *ClusterGraph, diff *GraphDiff, opts DiffOptions, layoutName string) *RFGraph {
+	before, after = normalizeGraph(before), normalizeGraph(after)
+	bi, ai := indexGraph(before, opts), indexGraph(after, opts)
+
+	nodeStatus := map[string]NodeDiff{}
+	for _, nd := range diff.Nodes {
+		nodeStatus[nd.Status+"|"+nd.ID] = nd
+	}
+	edgeStatus := map[string]EdgeDiff{}
+	for _, ed := range diff.Edges {
+		edgeStatus[ed.Status+"|"+ed.ID] = ed
+	}
+
+	overlay := &ClusterGraph{Nodes: []GraphNode{}, Edges: []GraphEdge{}, Warnings: after.Warnings}
+	present := map[string]bool{}
+	for _, key := range ai.nodeOrder {
+		n := *ai.nodes[key]
+		n.Data = withDiff(n.Data, DiffUnchanged, nil)
+		for _, status := range []string{DiffAdded, DiffChanged} {
+			if nd, ok := nodeStatus[status+"|"+n.ID]; ok {
+				n.Data = withDiff(n.Data, status, nd.Changes)
+			}
+		}
+		overlay.Nodes = append(overlay.Nodes, n)
+		present[n.ID] = true
+	}
+
+	// Nós removidos entram com o ID de before; o pai é traduzido para o
+	// equivalente em after quando os grafos são pareados por kind/nome
+	beforeToAfter := map[string]string{}
+	for id, key := range bi.nodeKeys {
+		if n, ok := ai.nodes[key]; ok {
+			beforeToAfter[id] = n.ID
+		}
+	}
+	for _, key := range bi.nodeOrder {
+		if _, ok := ai.nodes[key]; ok {
+			continue
+		}
+		n := *bi.nodes[key]
+		if p, ok := beforeToAfter[n.Parent]; ok {
+			n.Parent = p
+		}
+		n.Data = withDiff(n.Data, DiffRemoved, nil)
+		overlay.Nodes = append(overlay.Nodes, n)
+		present[n.ID] = true
+	}
+
+	for _, key := range ai.edgeOrder {
+		e := *ai.edges[key]
+		e.Data = withDiff(e.Data, DiffUnchanged, nil)
+		for _, status := range []string{DiffAdded, DiffChanged} {
+			if ed, ok := edgeStatus[status+"|"+e.ID]; ok {
+				e.Data = withDiff(e.Data, status, ed.Changes)
+			}
+		}
+		overlay.Edges = append(overlay.Edges, e)
+	}
+	for _, key := range bi.edgeOrder {
+		if _, ok := ai.edges[key]; ok {
+			continue
+		}
+		e := *bi.edges[key]
+		if s, ok := beforeToAfter[e.Source]; ok {
+			e.Source = s
+		}
+		if t, ok := beforeToAfter[e.Target]; ok {
+			e.Target = t
+		}
+		if !present[e.Source] || !present[e.Target] {
+			continue
+		}
+		e.ID = "removed:" + e.ID // não colide com uma aresta de after
+		e.Data = withDiff(e.Data, DiffRemoved, nil)
+		overlay.Edges = append(overlay.Edges, e)
+	}
+
+	return toRFGraph(overlay, layoutName)
+}
+
+func withDiff(data map[string]interface{}, status string, changes []FieldChange) map[string]interface{} {
+	out := make(map[string]interface{}, len(data)+2)
+	for k, v := range data {
+		out[k] = v
+	}
+	out["diff"] = status
+	if len(changes) > 0 {
+		out["diffChanges"] = changes
+	} else {
+		delete(out, "diffChanges")
+	}
+	return out
+}
@@ -0,0 +1,373 @@
+package k8s
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"text/template"
+
+	"k8s.io/apimachinery/pkg/labels"
+	"sigs.k8s.io/yaml"
+)
+
+/*
+========================
+ ARESTAS DECLARADAS (ANOTAÇÕES E REGRAS)
+========================
+*/
+
+// Dependências que não aparecem nos objetos (ex: "este Deployment chama aquele
+// Service externo") são declaradas por anotação no objeto de origem ou por
+// regras num arquivo carregado na inicialização (config.Config.EdgeRulesFile).
+// As arestas resultantes levam data.declared=true e data.declaredBy, para o
+// cliente distingui-las das observadas no cluster.
+
+// DependsOnAnnotation lista, separados por vírgula, os nós dos quais o objeto
+// depende: "svc/ns/nome", "deploy/nome" (mesmo namespace), "node/nome"...
+const DependsOnAnnotation = "topology.vkube.io/depends-on"
+
+// Origens de uma aresta declarada (data.declaredBy; regras usam "rule:<nome>").
+const declaredByAnnotation = "annotation"
+
+// refPrefixes traduz o kind de uma referência (abreviação do kubectl ou nome)
+// para o prefixo de ID do nó; clusterScopedPrefixes são os kinds sem namespace.
+var refPrefixes = map[string]string{
+	"deploy": "deploy", "deployment": "deploy",
+	"sts": "sts", "statefulset": "sts",
+	"ds": "ds", "daemonset": "ds",
+	"rs": "rs", "replicaset": "rs",
+	"po": "pod", "pod": "pod",
+	"svc": "svc", "service": "svc",
+	"job": "job",
+	"cj":  "cronjob", "cronjob": "cronjob",
+	"ing": "ing", "ingress": "ing",
+	"pvc": "pvc", "persistentvolumeclaim": "pvc",
+	"cm": "cm", "configmap": "cm",
+	"secret": "secret",
+	"no":     "node", "node": "node",
+	"pv": "pv", "persistentvolume": "pv",
+}
+
+var clusterScopedPrefixes = map[string]bool{"node": true, "pv": true}
+
+// EdgeRuleFile é o formato do arquivo de regras (YAML ou JSON):
+//
+//	rules:
+//	  - name: web-chama-pagamentos
+//	    source:
+//	      kind: Deployment
+//	      selector: app=web
+//	    target:
+//	      kind: Service
+//	      namespace: payments            # padrão: o namespace da origem
+//	      labels:
+//	        app: "{{ .Labels.backend }}"  # labels com "/" ou ".": {{ index .Labels "app.kubernetes.io/name" }}
+//	    type: calls
+type EdgeRuleFile struct {
+	Rules []EdgeRule `json:"rules"`
+}
+
+// EdgeRule liga cada nó que casa com Source aos nós que casam com Target.
+// Os kinds são os do grafo (os de TopologyOptions.Kinds) e Target exige Name
+// ou Labels. Os campos de Target são templates (text/template) avaliados com o nó de
+// origem (.Kind, .Name, .Namespace, .Labels); uma label ausente na origem (ou um
+// valor vazio) faz a regra não se aplicar a ela.
+type EdgeRule struct {
+	Name   string         `json:"name"`
+	Source EdgeRuleSource `json:"source"`
+	Target EdgeRuleTarget `json:"target"`
+	Type   string         `json:"type,omitempty"`  // padrão EdgeDependsOn
+	Label  string         `json:"label,omitempty"` // texto da aresta
+}
+
+type EdgeRuleSource struct {
+	Kind      string `json:"kind"`
+	Namespace string `json:"namespace,omitempty"` // vazio = qualquer
+	Selector  string `json:"selector,omitempty"`  // seletor de labels, ex: "app=web,tier!=db"
+}
+
+type EdgeRuleTarget struct {
+	Kind      string            `json:"kind"`
+	Namespace string            `json:"namespace,omitempty"` // template; vazio = namespace da origem
+	Name      string            `json:"name,omitempty"`      // template; vazio = qualquer
+	Labels    map[string]string `json:"labels,omitempty"`    // valores são templates
+}
+
+// edgeRule é a forma compilada de uma EdgeRule.
+type edgeRule struct {
+	EdgeRule
+	selector  labels.Selector
+	namespace *template.Template
+	name      *template.Template
+	labels    map[string]*template.Template
+}
+
+var (
+	edgeRulesMu sync.RWMutex
+	edgeRules   []*edgeRule
+)
+
+// LoadEdgeRules lê e valida o arquivo de regras.
+func LoadEdgeRules(path string) ([]EdgeRule, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao ler regras de arestas: %w", err)
+	}
+	var file EdgeRuleFile
+	if err := yaml.UnmarshalStrict(data, &file); err != nil {
+		return nil, fmt.Errorf("regras de arestas inválidas: %w", err)
+	}
+	if _, err := compileEdgeRules(file.Rules); err != nil {
+		return nil, err
+	}
+	return file.Rules, nil
+}
+
+// SetEdgeRules troca as regras usadas na construção dos grafos.
+func SetEdgeRules(rules []EdgeRule) error {
+	compiled, err := compileEdgeRules(rules)
+	if err != nil {
+		return err
+	}
+	edgeRulesMu.Lock()
+	edgeRules = compiled
+	edgeRulesMu.Unlock()
+	return nil
+}
+
+func compileEdgeRules(rules []EdgeRule) ([]*edgeRule, error) {
+	out := make([]*edgeRule, 0, len(rules))
+	for i, r := range rules {
+		name := r.Name
+		if name == "" {
+			name = fmt.Sprintf("#%d", i+1)
+			r.Name = name
+		}
+		if r.Source.Kind == "" || r.Target.Kind == "" {
+			return nil, fmt.Errorf("regra %s: source.kind e target.kind são obrigatórios", name)
+		}
+		var err error
+		if r.Source.Kind, err = canonicalKind(r.Source.Kind); err != nil {
+			return nil, fmt.Errorf("regra %s: source.kind: %w", name, err)
+		}
+		if r.Target.Kind, err = canonicalKind(r.Target.Kind); err != nil {
+			return nil, fmt.Errorf("regra %s: target.kind: %w", name, err)
+		}
+		// Sem nome nem labels, cada origem ligaria a todos os nós do kind
+		if r.Target.Name == "" && len(r.Target.Labels) == 0 {
+			return nil, fmt.Errorf("regra %s: target precisa de name ou labels", name)
+		}
+		if r.Type == "" {
+			r.Type = EdgeDependsOn
+		}
+		c := &edgeRule{EdgeRule: r, selector: labels.Everything(), labels: map[string]*template.Template{}}
+		if r.Source.Selector != "" {
+			if c.selector, err = labels.Parse(r.Source.Selector); err != nil {
+				return nil, fmt.Errorf("regra %s: selector inválido: %w", name, err)
+			}
+		}
+		if c.namespace, err = parseRuleTemplate(r.Target.Namespace); err != nil {
+			return nil, fmt.Errorf("regra %s: target.namespace: %w", name, err)
+		}
+		if c.name, err = parseRuleTemplate(r.Target.Name); err != nil {
+			return nil, fmt.Errorf("regra %s: target.name: %w", name, err)
+		}
+		for k, v := range r.Target.Labels {
+			if c.labels[k], err = parseRuleTemplate(v); err != nil {
+				return nil, fmt.Errorf("regra %s: target.labels.%s: %w", name, k, err)
+			}
+		}
+		out = append(out, c)
+	}
+	return out, nil
+}
+
+func parseRuleTemplate(text string) (*template.Template, error) {
+	if text == "" {
+		return nil, nil
+	}
+	return template.New("").Option("missingkey=error").Parse(text)
+}
+
+// render avalia o template com o nó de origem; ok=false se faltou algum valor.
+func render(t *template.Template, n *GraphNode) (string, bool) {
+	var buf bytes.Buffer
+	err := t.Execute(&buf, map[string]interface{}{
+		"Kind": n.Kind, "Name": n.Name, "Namespace": n.Namespace, "Labels": labelsOrEmpty(n.Labels),
+	})
+	if err != nil {
+		return "", false
+	}
+	return buf.String(), true
+}
+
+func labelsOrEmpty(l map[string]string) map[string]string {
+	if l == nil {
+		return map[string]string{}
+	}
+	return l
+}
+
+// dependsOnRef é uma entrada de DependsOnAnnotation e o ID do nó referenciado
+// (vazio se a entrada é inválida).
+type dependsOnRef struct {
+	raw string
+	id  string
+}
+
+// parseDependsOn lê o valor de DependsOnAnnotation; ns é o namespace do objeto anotado.
+func parseDependsOn(value, ns string) []dependsOnRef {
+	var refs []dependsOnRef
+	for _, raw := range strings.Split(value, ",") {
+		raw = strings.TrimSpace(raw)
+		if raw == "" {
+			continue
+		}
+		ref := dependsOnRef{raw: raw}
+		parts := strings.Split(raw, "/")
+		prefix, ok := refPrefixes[strings.ToLower(parts[0])]
+		switch {
+		case !ok:
+		case clusterScopedPrefixes[prefix] && len(parts) == 2:
+			ref.id = prefix + ":" + parts[1]
+		case !clusterScopedPrefixes[prefix] && len(parts) == 2:
+			ref.id = prefix + ":" + ns + ":" + parts[1]
+		case !clusterScopedPrefixes[prefix] && len(parts) == 3:
+			ref.id = prefix + ":" + parts[1] + ":" + parts[2]
+		}
+		refs = append(refs, ref)
+	}
+	return refs
+}
+
+// addDeclaredEdges adiciona as arestas das anotações DependsOnAnnotation e das
+// regras configuradas. Referências a nós que não estão no grafo (removidos,
+// filtrados ou inválidos) ficam em data.unresolvedDependencies da origem.
+func addDeclaredEdges(g *ClusterGraph, res *clusterResources) {
+	edgeRulesMu.RLock()
+	rules := edgeRules
+	edgeRulesMu.RUnlock()
+
+	index := make(map[string]int, len(g.Nodes))
+	for i, n := range g.Nodes {
+		index[n.ID] = i
+	}
+	seen := map[string]bool{}
+	addEdge := func(source, target, edgeType, label, by string) {
+		id := "edge:declared:" + edgeType + ":" + source + "->" + target
+		if source == target || seen[id] {
+			return
+		}
+		seen[id] = true
+		g.Edges = append(g.Edges, GraphEdge{
+			ID:     id,
+			Source: source,
+			Target: target,
+			Type:   edgeType,
+			Label:  label,
+			Data:   map[string]interface{}{"declared": true, "declaredBy": by},
+		})
+	}
+
+	// Anotações
+	unresolved := map[string][]string{}
+	for _, o := range graphObjects(res) {
+		value, ok := o.obj.GetAnnotations()[DependsOnAnnotation]
+		if _, inGraph := index[o.id]; !ok || !inGraph {
+			continue
+		}
+		for _, ref := range parseDependsOn(value, o.obj.GetNamespace()) {
+			if _, ok := index[ref.id]; !ok {
+				unresolved[o.id] = append(unresolved[o.id], ref.raw)
+				continue
+			}
+			addEdge(o.id, ref.id, EdgeDependsOn, "", declaredByAnnotation)
+		}
+	}
+	for id, refs := range unresolved {
+		n := &g.Nodes[index[id]]
+		data := make(map[string]interface{}, len(n.Data)+1)
+		for k, v := range n.Data {
+			data[k] = v
+		}
+		data["unresolvedDependencies"] = refs
+		n.Data = data
+	}
+
+	// Regras
+	if len(rules) == 0 {
+		return
+	}
+	byKind := map[string][]int{}
+	for i, n := range g.Nodes {
+		if !n.Group {
+			byKind[n.Kind] = append(byKind[n.Kind], i)
+		}
+	}
+	for _, r := range rules {
+		for _, si := range byKind[r.Source.Kind] {
+			src := &g.Nodes[si]
+			if r.Source.Namespace != "" && src.Namespace != r.Source.Namespace {
+				continue
+			}
+			if !r.selector.Matches(labels.Set(src.Labels)) {
+				continue
+			}
+			target, ok := r.renderTarget(src)
+			if !ok {
+				continue
+			}
+			for _, ti := range byKind[r.Target.Kind] {
+				if target.matches(&g.Nodes[ti]) {
+					addEdge(src.ID, g.Nodes[ti].ID, r.Type, r.Label, "rule:"+r.Name)
+				}
+			}
+		}
+	}
+}
+
+// renderedTarget é o alvo de uma regra já avaliado para um nó de origem.
+type renderedTarget struct {
+	namespace string
+	name      string
+	labels    map[string]string
+}
+
+func (r *edgeRule) renderTarget(src *GraphNode) (renderedTarget, bool) {
+	t := renderedTarget{namespace: src.Namespace, labels: make(map[string]string, len(r.labels))}
+	var ok bool
+	if r.namespace != nil {
+		if t.namespace, ok = render(r.namespace, src); !ok {
+			return t, false
+		}
+	}
+	if r.name != nil {
+		if t.name, ok = render(r.name, src); !ok || t.name == "" {
+			return t, false
+		}
+	}
+	for k, tmpl := range r.labels {
+		if t.labels[k], ok = render(tmpl, src); !ok || t.labels[k] == "" {
+			return t, false
+		}
+	}
+	return t, true
+}
+
+// matches compara o nó com o alvo; nós cluster-scoped ignoram o namespace.
+func (t renderedTarget) matches(n *GraphNode) bool {
+	if n.Namespace != "" && n.Namespace != t.namespace {
+		return false
+	}
+	if t.name != "" && n.Name != t.name {
+		return false
+	}
+	for k, v := range t.labels {
+		if n.Labels[k] != v {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,148 @@
+package k8s
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseDependsOn(t *testing.T) {
+	tests := []struct {
+		name  string
+		value string
+		want  []dependsOnRef
+	}{
+		{
+			name:  "mesmo namespace",
+			value: "deploy/api",
+			want:  []dependsOnRef{{raw: "deploy/api", id: "deploy:shop:api"}},
+		},
+		{
+			name:  "namespace explícito e nome longo do kind",
+			value: "Service/payments/gateway",
+			want:  []dependsOnRef{{raw: "Service/payments/gateway", id: "svc:payments:gateway"}},
+		},
+		{
+			name:  "cluster-scoped ignora o namespace do objeto",
+			value: "node/n1, pv/data-01",
+			want: []dependsOnRef{
+				{raw: "node/n1", id: "node:n1"},
+				{raw: "pv/data-01", id: "pv:data-01"},
+			},
+		},
+		{
+			name:  "entradas vazias são ignoradas",
+			value: " svc/db ,, ",
+			want:  []dependsOnRef{{raw: "svc/db", id: "svc:shop:db"}},
+		},
+		{
+			name:  "entradas inválidas ficam sem ID",
+			value: "bogus/x,node/ns/n1,svc,svc/a/b/c",
+			want: []dependsOnRef{
+				{raw: "bogus/x"},
+				{raw: "node/ns/n1"},
+				{raw: "svc"},
+				{raw: "svc/a/b/c"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := parseDependsOn(tt.value, "shop"); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("parseDependsOn(%q) = %v, want %v", tt.value, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRenderRuleTemplate(t *testing.T) {
+	src := &GraphNode{
+		Kind:      "Deployment",
+		Name:      "web",
+		Namespace: "shop",
+		Labels:    map[string]string{"backend": "api", "app.kubernetes.io/name": "web"},
+	}
+
+	tests := []struct {
+		name   string
+		text   string
+		node   *GraphNode
+		want   string
+		wantOK bool
+	}{
+		{name: "campos do nó", text: "{{ .Namespace }}-{{ .Name }}", node: src, want: "shop-web", wantOK: true},
+		{name: "label", text: "{{ .Labels.backend }}", node: src, want: "api", wantOK: true},
+		{name: "label com ponto e barra", text: `{{ index .Labels "app.kubernetes.io/name" }}`, node: src, want: "web", wantOK: true},
+		{name: "label ausente (missingkey=error)", text: "{{ .Labels.missing }}", node: src, wantOK: false},
+		{name: "nó sem labels", text: "{{ .Labels.backend }}", node: &GraphNode{Kind: "Deployment", Name: "x"}, wantOK: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tmpl, err := parseRuleTemplate(tt.text)
+			if err != nil {
+				t.Fatalf("parseRuleTemplate(%q): %v", tt.text, err)
+			}
+			got, ok := render(tmpl, tt.node)
+			if ok != tt.wantOK || (ok && got != tt.want) {
+				t.Errorf("render(%q) = %q, %v; want %q, %v", tt.text, got, ok, tt.want, tt.wantOK)
+			}
+		})
+	}
+}
+
+func TestCompileEdgeRules(t *testing.T) {
+	source := EdgeRuleSource{Kind: "Deployment"}
+
+	tests := []struct {
+		name    string
+		rule    EdgeRule
+		wantErr bool
+	}{
+		{
+			name: "alvo por labels",
+			rule: EdgeRule{Source: source, Target: EdgeRuleTarget{Kind: "Service", Labels: map[string]string{"app": "{{ .Labels.backend }}"}}},
+		},
+		{
+			name: "kind sem diferenciar maiúsculas",
+			rule: EdgeRule{Source: EdgeRuleSource{Kind: "deployment"}, Target: EdgeRuleTarget{Kind: "service", Name: "db"}},
+		},
+		{
+			name:    "kind desconhecido na origem",
+			rule:    EdgeRule{Source: EdgeRuleSource{Kind: "Deploymnet"}, Target: EdgeRuleTarget{Kind: "Service", Name: "db"}},
+			wantErr: true,
+		},
+		{
+			name:    "kind desconhecido no alvo",
+			rule:    EdgeRule{Source: source, Target: EdgeRuleTarget{Kind: "Svc", Name: "db"}},
+			wantErr: true,
+		},
+		{
+			name:    "alvo sem name nem labels",
+			rule:    EdgeRule{Source: source, Target: EdgeRuleTarget{Kind: "Service", Namespace: "payments"}},
+			wantErr: true,
+		},
+		{
+			name:    "template inválido",
+			rule:    EdgeRule{Source: source, Target: EdgeRuleTarget{Kind: "Service", Name: "{{ .Name"}},
+			wantErr: true,
+		},
+		{
+			name:    "seletor inválido",
+			rule:    EdgeRule{Source: EdgeRuleSource{Kind: "Deployment", Selector: "app in"}, Target: EdgeRuleTarget{Kind: "Service", Name: "db"}},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			compiled, err := compileEdgeRules([]EdgeRule{tt.rule})
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("compileEdgeRules() err = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err == nil && (compiled[0].Source.Kind != "Deployment" || compiled[0].Type != EdgeDependsOn) {
+				t.Errorf("regra compilada = %+v", compiled[0].EdgeRule)
+			}
+		})
+	}
+}
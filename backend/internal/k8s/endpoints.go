@@ -0,0 +1,184 @@
+package k8s
+
+import (
+	"sort"
+
+	corev1 "k8s.io/api/core/v1"
+	discoveryv1 "k8s.io/api/discovery/v1"
+)
+
+/*
+========================
+ SERVICE -> POD (ENDPOINTSLICES)
+========================
+*/
+
+// Origem de uma aresta Service -> Pod (data.source).
+const (
+	endpointSourceSlice    = "endpointslice"
+	endpointSourceSelector = "selector" // fallback quando o Service não tem slices
+)
+
+// endpointState é o estado agregado de um pod em todas as slices de um
+// Service (em dual-stack o mesmo pod aparece em uma slice por família de IP).
+type endpointState struct {
+	ready, serving, terminating bool
+	addresses                   []string
+}
+
+// addServiceEndpoints liga cada Service aos pods que ele de fato roteia, a
+// partir das EndpointSlices (discovery/v1). Isso cobre Services sem selector
+// e endpoints gerenciados manualmente, e cada aresta informa se o endpoint
+// está ready/serving/terminating. Services sem nenhuma slice (ex: sem
+// permissão para listar EndpointSlices) caem no match de selector.
+// Os nós de Service recebem as contagens e noReadyEndpoints.
+func addServiceEndpoints(g *ClusterGraph, res *clusterResources, podsByNs map[string][]*corev1.Pod) {
+	slicesBySvc := map[string][]*discoveryv1.EndpointSlice{}
+	for _, s := range res.EndpointSlices {
+		svcName := s.Labels[discoveryv1.LabelServiceName]
+		if svcName == "" {
+			continue
+		}
+		key := s.Namespace + "/" + svcName
+		slicesBySvc[key] = append(slicesBySvc[key], s)
+	}
+
+	podExists := make(map[string]bool, len(res.Pods))
+	for _, p := range res.Pods {
+		podExists[p.Namespace+"/"+p.Name] = true
+	}
+
+	svcData := make(map[string]map[string]interface{}, len(res.Services))
+	for _, svc := range res.Services {
+		svcID := "svc:" + svc.Namespace + ":" + svc.Name
+		slices := slicesBySvc[svc.Namespace+"/"+svc.Name]
+
+		states := map[string]*endpointState{}
+		podOrder := []string{}
+		external := []string{}
+		externalReady := 0
+		source := endpointSourceSlice
+
+		if len(slices) > 0 {
+			for _, slice := range slices {
+				for _, ep := range slice.Endpoints {
+					if ep.TargetRef == nil || ep.TargetRef.Kind != "Pod" {
+						// Endpoint manual (IP externo, VM...): não há nó para ligar
+						external = append(external, ep.Addresses...)
+						if ready, _, _ := endpointConditions(ep.Conditions); ready {
+							externalReady++
+						}
+						continue
+					}
+					st, ok := states[ep.TargetRef.Name]
+					if !ok {
+						st = &endpointState{}
+						states[ep.TargetRef.Name] = st
+						podOrder = append(podOrder, ep.TargetRef.Name)
+					}
+					ready, serving, terminating := endpointConditions(ep.Conditions)
+					st.ready = st.ready || ready
+					st.serving = st.serving || serving
+					st.terminating = st.terminating || terminating
+					st.addresses = append(st.addresses, ep.Addresses...)
+				}
+			}
+			sort.Strings(podOrder)
+		} else {
+			source = endpointSourceSelector
+			for _, pod := range podsByNs[svc.Namespace] {
+				if !podMatchesSelector(pod.Labels, svc.Spec.Selector) {
+					continue
+				}
+				ready := podReady(pod)
+				states[pod.Name] = &endpointState{
+					ready:       ready,
+					serving:     ready,
+					terminating: pod.DeletionTimestamp != nil,
+				}
+				if pod.Status.PodIP != "" {
+					states[pod.Name].addresses = []string{pod.Status.PodIP}
+				}
+				podOrder = append(podOrder, pod.Name)
+			}
+		}
+
+		readyCount := externalReady
+		for _, name := range podOrder {
+			st := states[name]
+			if st.ready {
+				readyCount++
+			}
+			data := map[string]interface{}{
+				"ready":       st.ready,
+				"serving":     st.serving,
+				"terminating": st.terminating,
+				"source":      source,
+			}
+			if len(st.addresses) > 0 {
+				data["addresses"] = st.addresses
+			}
+			if !podExists[svc.Namespace+"/"+name] {
+				continue // pod fora do cache (ex: recém-criado): conta, mas sem aresta
+			}
+			g.Edges = append(g.Edges, GraphEdge{
+				ID:     "edge:svc->pod:" + svc.Namespace + ":" + svc.Name + "->" + name,
+				Source: svcID,
+				Target: "pod:" + svc.Namespace + ":" + name,
+				Type:   EdgeSelects,
+				Data:   data,
+			})
+		}
+
+		data := map[string]interface{}{
+			"type":             string(svc.Spec.Type),
+			"endpoints":        len(podOrder) + len(external),
+			"readyEndpoints":   readyCount,
+			"endpointSource":   source,
+			"noReadyEndpoints": false,
+		}
+		if len(external) > 0 {
+			data["externalEndpoints"] = external
+		}
+		// ExternalName não tem endpoints por definição
+		if svc.Spec.Type != corev1.ServiceTypeExternalName {
+			data["noReadyEndpoints"] = readyCount == 0
+		}
+		svcData[svcID] = data
+	}
+
+	for i := range g.Nodes {
+		data, ok := svcData[g.Nodes[i].ID]
+		if !ok {
+			continue
+		}
+		if g.Nodes[i].Data == nil {
+			g.Nodes[i].Data = map[string]interface{}{}
+		}
+		for k, v := range data {
+			g.Nodes[i].Data[k] = v
+		}
+	}
+}
+
+// endpointConditions aplica os defaults da API: ready nil = true,
+// serving nil = ready, terminating nil = false.
+func endpointConditions(c discoveryv1.EndpointConditions) (ready, serving, terminating bool) {
+	ready = c.Ready == nil || *c.Ready
+	serving = ready
+	if c.Serving != nil {
+		serving = *c.Serving
+	}
+	terminating = c.Terminating != nil && *c.Terminating
+	return ready, serving, terminating
+}
+
+// podReady indica se a condição Ready do pod está True.
+func podReady(pod *corev1.Pod) bool {
+	for _, c := range pod.Status.Conditions {
+		if c.Type == corev1.PodReady {
+			return c.Status == corev1.ConditionTrue
+		}
+	}
+	return false
+}
@@ -0,0 +1,432 @@
+package k8s
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+)
+
+/*
+========================
+ EXPORTAÇÃO (DOT, GRAPHML, MERMAID, CYTOSCAPE, JSON)
+========================
+*/
+
+// Formatos disponíveis via parâmetro format= da rota de topologia. Sem format,
+// a rota continua respondendo com o RFGraph do React Flow.
+const (
+	FormatDOT       = "dot"
+	FormatGraphML   = "graphml"
+	FormatMermaid   = "mermaid"
+	FormatCytoscape = "cytoscape"
+	FormatJSON      = "json" // ClusterGraph puro, sem layout
+	FormatSVG       = "svg"  // imagem autocontida com as posições do layout
+)
+
+// ExportOptions são as opções de exportação vindas da requisição.
+type ExportOptions struct {
+	Layout string // usado por formatos que carregam posições (cytoscape, svg)
+}
+
+// Exporter serializa um ClusterGraph em um formato externo. Os grupos
+// (GraphNode.Group/Parent: namespaces, regiões, zonas) devem ser preservados
+// como agrupamentos do formato, e o tipo de cada aresta (GraphEdge.Type) mantido.
+type Exporter interface {
+	ContentType() string
+	Export(w io.Writer, g *ClusterGraph, opts ExportOptions) error
+}
+
+var exporters = map[string]Exporter{
+	FormatDOT:       dotExporter{},
+	FormatGraphML:   graphMLExporter{},
+	FormatMermaid:   mermaidExporter{},
+	FormatCytoscape: cytoscapeExporter{},
+	FormatJSON:      jsonExporter{},
+	FormatSVG:       svgExporter{},
+}
+
+// RegisterExporter adiciona (ou substitui) um formato. Deve ser chamado na
+// inicialização, antes de o servidor atender requisições.
+func RegisterExporter(format string, e Exporter) {
+	exporters[format] = e
+}
+
+// GetExporter retorna o exporter do formato recebido pela API.
+func GetExporter(format string) (Exporter, error) {
+	e, ok := exporters[format]
+	if !ok {
+		return nil, fmt.Errorf("formato desconhecido: %s (disponíveis: %s)", format, strings.Join(ExportFormats(), ", "))
+	}
+	return e, nil
+}
+
+// ExportFormats lista os formatos registrados, em ordem alfabética.
+func ExportFormats() []string {
+	return sortedKeys(exporters)
+}
+
+// kindColors são as cores de preenchimento por kind nos formatos com estilo.
+var kindColors = map[string]string{
+	"Cluster":               "#fafafa",
+	"Namespace":             "#f5f5f5",
+	"Region":                "#eceff1",
+	"Zone":                  "#e3f2fd",
+	"Node":                  "#cfd8dc",
+	"Deployment":            "#bbdefb",
+	"StatefulSet":           "#c5cae9",
+	"DaemonSet":             "#d1c4e9",
+	"ReplicaSet":            "#e1f5fe",
+	"Job":                   "#ffe0b2",
+	"CronJob":               "#ffcc80",
+	"Pod":                   "#c8e6c9",
+	"Service":               "#fff9c4",
+	"Ingress":               "#ffecb3",
+	"Gateway":               "#ffe082",
+	"HTTPRoute":             "#fff59d",
+	"HPA":                   "#f8bbd0",
+	"PersistentVolumeClaim": "#d7ccc8",
+	"PersistentVolume":      "#bcaaa4",
+	"StorageClass":          "#a1887f",
+	"ConfigMap":             "#dcedc8",
+	"Secret":                "#ffcdd2",
+}
+
+func kindColor(kind string) string {
+	if c, ok := kindColors[kind]; ok {
+		return c
+	}
+	return "#ffffff"
+}
+
+// groupTree indexa os filhos de cada nó; raízes são os nós sem pai presente no grafo.
+type groupTree struct {
+	children map[string][]*GraphNode
+	roots    []*GraphNode
+}
+
+func buildGroupTree(g *ClusterGraph) *groupTree {
+	exists := make(map[string]bool, len(g.Nodes))
+	for _, n := range g.Nodes {
+		exists[n.ID] = true
+	}
+	t := &groupTree{children: map[string][]*GraphNode{}}
+	for i := range g.Nodes {
+		n := &g.Nodes[i]
+		if n.Parent != "" && exists[n.Parent] {
+			t.children[n.Parent] = append(t.children[n.Parent], n)
+		} else {
+			t.roots = append(t.roots, n)
+		}
+	}
+	return t
+}
+
+// hasChildren indica se o nó deve virar um agrupamento no formato exportado.
+func (t *groupTree) hasChildren(n *GraphNode) bool {
+	return n.Group || len(t.children[n.ID]) > 0
+}
+
+// nodeLabel é o rótulo padrão de um nó nos formatos exportados.
+func nodeLabel(n *GraphNode) string {
+	return n.Kind + ": " + n.Name
+}
+
+// edgeLabel prefere o label da aresta (ex: host/path) e cai no tipo.
+func edgeLabel(e *GraphEdge) string {
+	if e.Label != "" {
+		return e.Label
+	}
+	return e.Type
+}
+
+/* ==== DOT (Graphviz) ==== */
+
+type dotExporter struct{}
+
+func (dotExporter) ContentType() string { return "text/vnd.graphviz; charset=utf-8" }
+
+func (dotExporter) Export(w io.Writer, g *ClusterGraph, _ ExportOptions) error {
+	bw := bufio.NewWriter(w)
+	fmt.Fprintln(bw, "digraph topology {")
+	fmt.Fprintln(bw, "  rankdir=LR;")
+	fmt.Fprintln(bw, "  compound=true;")
+	fmt.Fprintln(bw, `  node [shape=box, style="rounded,filled", fontname="Helvetica"];`)
+	fmt.Fprintln(bw, `  edge [fontname="Helvetica", fontsize=10];`)
+	for _, warn := range g.Warnings {
+		fmt.Fprintf(bw, "  // aviso: %s\n", strings.ReplaceAll(warn.Message, "\n", " "))
+	}
+
+	tree := buildGroupTree(g)
+	var writeNode func(n *GraphNode, indent string)
+	writeNode = func(n *GraphNode, indent string) {
+		if !tree.hasChildren(n) {
+			fmt.Fprintf(bw, "%s%s [label=%s, class=%s, fillcolor=%s];\n",
+				indent, dotQuote(n.ID), dotQuote(n.Kind+"\n"+n.Name), dotQuote(n.Kind), dotQuote(kindColor(n.Kind)))
+			return
+		}
+		// Clusters do Graphviz precisam do prefixo "cluster"
+		fmt.Fprintf(bw, "%ssubgraph %s {\n", indent, dotQuote("cluster_"+n.ID))
+		fmt.Fprintf(bw, "%s  label=%s;\n", indent, dotQuote(nodeLabel(n)))
+		fmt.Fprintf(bw, "%s  class=%s;\n", indent, dotQuote(n.Kind))
+		fmt.Fprintf(bw, "%s  style=\"rounded,filled\";\n", indent)
+		fmt.Fprintf(bw, "%s  fillcolor=%s;\n", indent, dotQuote(kindColor(n.Kind)))
+		for _, child := range tree.children[n.ID] {
+			writeNode(child, indent+"  ")
+		}
+		fmt.Fprintf(bw, "%s}\n", indent)
+	}
+	for _, n := range tree.roots {
+		writeNode(n, "  ")
+	}
+
+	// Arestas que tocam um agrupamento não existem no DOT (o grupo vira subgraph)
+	leaves := make(map[string]bool, len(g.Nodes))
+	for i := range g.Nodes {
+		leaves[g.Nodes[i].ID] = !tree.hasChildren(&g.Nodes[i])
+	}
+	for i := range g.Edges {
+		e := &g.Edges[i]
+		if !leaves[e.Source] || !leaves[e.Target] {
+			continue
+		}
+		fmt.Fprintf(bw, "  %s -> %s [label=%s, class=%s];\n",
+			dotQuote(e.Source), dotQuote(e.Target), dotQuote(edgeLabel(e)), dotQuote(e.Type))
+	}
+	fmt.Fprintln(bw, "}")
+	return bw.Flush()
+}
+
+func dotQuote(s string) string {
+	s = strings.ReplaceAll(s, `\`, `\\`)
+	s = strings.ReplaceAll(s, `"`, `\"`)
+	s = strings.ReplaceAll(s, "\n", `\n`)
+	return `"` + s + `"`
+}
+
+/* ==== GRAPHML ==== */
+
+type graphMLExporter struct{}
+
+func (graphMLExporter) ContentType() string { return "application/graphml+xml; charset=utf-8" }
+
+func (graphMLExporter) Export(w io.Writer, g *ClusterGraph, _ ExportOptions) error {
+	bw := bufio.NewWriter(w)
+	fmt.Fprintln(bw, `<?xml version="1.0" encoding="UTF-8"?>`)
+	fmt.Fprintln(bw, `<graphml xmlns="http://graphml.graphdrawing.org/xmlns">`)
+	for _, key := range [][3]string{
+		{"kind", "node", "kind"},
+		{"name", "node", "name"},
+		{"namespace", "node", "namespace"},
+		{"health", "node", "health"},
+		{"label", "node", "label"},
+		{"etype", "edge", "type"},
+		{"elabel", "edge", "label"},
+	} {
+		fmt.Fprintf(bw, `  <key id="%s" for="%s" attr.name="%s" attr.type="string"/>`+"\n", key[0], key[1], key[2])
+	}
+	for _, warn := range g.Warnings {
+		fmt.Fprintf(bw, "  <!-- aviso: %s -->\n", xmlEscape(strings.ReplaceAll(warn.Message, "--", "- -")))
+	}
+	fmt.Fprintln(bw, `  <graph id="topology" edgedefault="directed">`)
+
+	tree := buildGroupTree(g)
+	emitted := make(map[string]bool, len(g.Nodes))
+	var writeNode func(n *GraphNode, indent string)
+	writeNode = func(n *GraphNode, indent string) {
+		emitted[n.ID] = true
+		fmt.Fprintf(bw, "%s<node id=\"%s\">\n", indent, xmlEscape(n.ID))
+		fmt.Fprintf(bw, "%s  <data key=\"kind\">%s</data>\n", indent, xmlEscape(n.Kind))
+		fmt.Fprintf(bw, "%s  <data key=\"name\">%s</data>\n", indent, xmlEscape(n.Name))
+		fmt.Fprintf(bw, "%s  <data key=\"label\">%s</data>\n", indent, xmlEscape(nodeLabel(n)))
+		if n.Namespace != "" {
+			fmt.Fprintf(bw, "%s  <data key=\"namespace\">%s</data>\n", indent, xmlEscape(n.Namespace))
+		}
+		if h, ok := n.Data["health"].(string); ok {
+			fmt.Fprintf(bw, "%s  <data key=\"health\">%s</data>\n", indent, xmlEscape(h))
+		}
+		// Grupos viram grafos aninhados (yEd/Gephi mostram como contêineres)
+		if children := tree.children[n.ID]; len(children) > 0 {
+			fmt.Fprintf(bw, "%s  <graph id=\"%s\" edgedefault=\"directed\">\n", indent, xmlEscape(n.ID+"::"))
+			for _, child := range children {
+				writeNode(child, indent+"    ")
+			}
+			fmt.Fprintf(bw, "%s  </graph>\n", indent)
+		}
+		fmt.Fprintf(bw, "%s</node>\n", indent)
+	}
+	for _, n := range tree.roots {
+		writeNode(n, "    ")
+	}
+
+	// Arestas para nós que não foram emitidos deixariam o GraphML inválido
+	for i := range g.Edges {
+		e := &g.Edges[i]
+		if !emitted[e.Source] || !emitted[e.Target] {
+			continue
+		}
+		fmt.Fprintf(bw, "    <edge id=\"%s\" source=\"%s\" target=\"%s\">\n", xmlEscape(e.ID), xmlEscape(e.Source), xmlEscape(e.Target))
+		fmt.Fprintf(bw, "      <data key=\"etype\">%s</data>\n", xmlEscape(e.Type))
+		if e.Label != "" {
+			fmt.Fprintf(bw, "      <data key=\"elabel\">%s</data>\n", xmlEscape(e.Label))
+		}
+		fmt.Fprintln(bw, "    </edge>")
+	}
+	fmt.Fprintln(bw, "  </graph>")
+	fmt.Fprintln(bw, "</graphml>")
+	return bw.Flush()
+}
+
+var xmlReplacer = strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;", `"`, "&quot;", "'", "&apos;")
+
+func xmlEscape(s string) string {
+	return xmlReplacer.Replace(s)
+}
+
+/* ==== MERMAID ==== */
+
+type mermaidExporter struct{}
+
+func (mermaidExporter) ContentType() string { return "text/plain; charset=utf-8" }
+
+func (mermaidExporter) Export(w io.Writer, g *ClusterGraph, _ ExportOptions) error {
+	bw := bufio.NewWriter(w)
+	fmt.Fprintln(bw, "flowchart LR")
+	for _, warn := range g.Warnings {
+		fmt.Fprintf(bw, "  %%%% aviso: %s\n", strings.ReplaceAll(warn.Message, "\n", " "))
+	}
+
+	// IDs do Mermaid não aceitam ":" e "/": usa n0, n1... na ordem do grafo
+	ids := make(map[string]string, len(g.Nodes))
+	for i, n := range g.Nodes {
+		ids[n.ID] = fmt.Sprintf("n%d", i)
+	}
+
+	tree := buildGroupTree(g)
+	kinds := map[string][]string{}
+	var writeNode func(n *GraphNode, indent string)
+	writeNode = func(n *GraphNode, indent string) {
+		if !tree.hasChildren(n) {
+			fmt.Fprintf(bw, "%s%s[\"%s\"]\n", indent, ids[n.ID], mermaidEscape(nodeLabel(n)))
+			kinds[n.Kind] = append(kinds[n.Kind], ids[n.ID])
+			return
+		}
+		fmt.Fprintf(bw, "%ssubgraph %s[\"%s\"]\n", indent, ids[n.ID], mermaidEscape(nodeLabel(n)))
+		for _, child := range tree.children[n.ID] {
+			writeNode(child, indent+"  ")
+		}
+		fmt.Fprintf(bw, "%send\n", indent)
+	}
+	for _, n := range tree.roots {
+		writeNode(n, "  ")
+	}
+
+	for i := range g.Edges {
+		e := &g.Edges[i]
+		src, okSrc := ids[e.Source]
+		dst, okDst := ids[e.Target]
+		if !okSrc || !okDst {
+			continue
+		}
+		fmt.Fprintf(bw, "  %s -->|\"%s\"| %s\n", src, mermaidEscape(edgeLabel(e)), dst)
+	}
+
+	// Uma classe por kind, com a mesma cor dos outros formatos
+	for _, kind := range sortedKeys(kinds) {
+		fmt.Fprintf(bw, "  classDef %s fill:%s,stroke:#607d8b\n", kind, kindColor(kind))
+		fmt.Fprintf(bw, "  class %s %s\n", strings.Join(kinds[kind], ","), kind)
+	}
+	return bw.Flush()
+}
+
+func mermaidEscape(s string) string {
+	return strings.ReplaceAll(s, `"`, "#quot;")
+}
+
+/* ==== CYTOSCAPE.JS ==== */
+
+type cytoscapeExporter struct{}
+
+func (cytoscapeExporter) ContentType() string { return "application/json; charset=utf-8" }
+
+type cyElement struct {
+	Data     map[string]interface{} `json:"data"`
+	Position *Point                 `json:"position,omitempty"`
+	Classes  string                 `json:"classes,omitempty"`
+}
+
+// Export gera o formato "elements" do Cytoscape.js. Grupos viram compound
+// nodes (data.parent); com layout, os nós folha levam a posição absoluta.
+func (cytoscapeExporter) Export(w io.Writer, g *ClusterGraph, opts ExportOptions) error {
+	var layout *Layout
+	if opts.Layout != "" && opts.Layout != LayoutNone {
+		layout = ComputeLayout(g, opts.Layout)
+	}
+
+	tree := buildGroupTree(g)
+	nodes := make([]cyElement, 0, len(g.Nodes))
+	emitted := make(map[string]bool, len(g.Nodes))
+	for _, n := range nodesParentsFirst(g.Nodes) {
+		emitted[n.ID] = true
+		data := map[string]interface{}{}
+		for k, v := range n.Data {
+			data[k] = v
+		}
+		data["id"] = n.ID
+		data["label"] = nodeLabel(&n)
+		data["kind"] = n.Kind
+		data["name"] = n.Name
+		if n.Namespace != "" {
+			data["namespace"] = n.Namespace
+		}
+		if len(n.Labels) > 0 {
+			data["labels"] = n.Labels
+		}
+		el := cyElement{Data: data, Classes: n.Kind}
+		if n.Parent != "" && len(tree.children[n.Parent]) > 0 {
+			data["parent"] = n.Parent
+		}
+		if layout != nil && !tree.hasChildren(&n) {
+			if p, ok := layout.Positions[n.ID]; ok {
+				el.Position = &Point{X: p.X, Y: p.Y}
+			}
+		}
+		nodes = append(nodes, el)
+	}
+
+	// O Cytoscape.js recusa arestas com origem/destino inexistente
+	edges := make([]cyElement, 0, len(g.Edges))
+	for _, e := range g.Edges {
+		if !emitted[e.Source] || !emitted[e.Target] {
+			continue
+		}
+		data := map[string]interface{}{}
+		for k, v := range e.Data {
+			data[k] = v
+		}
+		data["id"] = e.ID
+		data["source"] = e.Source
+		data["target"] = e.Target
+		data["type"] = e.Type
+		if e.Label != "" {
+			data["label"] = e.Label
+		}
+		edges = append(edges, cyElement{Data: data, Classes: e.Type})
+	}
+
+	return json.NewEncoder(w).Encode(map[string]interface{}{
+		"elements": map[string]interface{}{"nodes": nodes, "edges": edges},
+		"warnings": g.Warnings,
+	})
+}
+
+/* ==== JSON (ClusterGraph) ==== */
+
+type jsonExporter struct{}
+
+func (jsonExporter) ContentType() string { return "application/json; charset=utf-8" }
+
+func (jsonExporter) Export(w io.Writer, g *ClusterGraph, _ ExportOptions) error {
+	return json.NewEncoder(w).Encode(g)
+}
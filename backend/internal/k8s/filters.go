@@ -0,0 +1,320 @@
+package k8s
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/apimachinery/pkg/labels"
+)
+
+/*
+========================
+ FILTROS DE RECURSOS (SELECTOR, FIELD SELECTOR, KIND, NOME, NAMESPACES)
+========================
+*/
+
+// filterableKinds são os kinds aceitos em TopologyOptions.Kinds/ExcludeKinds.
+var filterableKinds = []string{
+	"ConfigMap", "CronJob", "DaemonSet", "Deployment", "Gateway", "HPA", "HTTPRoute",
+	"Ingress", "Job", "Node", "PersistentVolume", "PersistentVolumeClaim", "Pod",
+	"ReplicaSet", "Secret", "Service", "StatefulSet", "StorageClass",
+}
+
+// resourceFilter é a forma compilada dos filtros de TopologyOptions. Roda
+// sobre clusterResources antes de buildClusterGraph: o que fica de fora não
+// entra no casamento de seletores nem na construção das arestas. Arestas
+// montadas por nome (HPA -> workload, Ingress -> Service...) que apontam para
+// um objeto filtrado são removidas no fim por dropDanglingEdges.
+//
+// Seletor de labels, seletor de campos e regex de nome valem para os recursos
+// namespaced. Nodes, PVs e StorageClasses só saem pelo filtro de kind, e
+// ConfigMaps/Secrets (que só aparecem quando referenciados) pelo filtro de
+// kind e pela lista de namespaces: seguem os workloads que ficaram.
+type resourceFilter struct {
+	namespaces map[string]bool // nil = sem lista de namespaces
+	selector   labels.Selector // nil = sem seletor
+	fieldSel   fields.Selector // nil = sem seletor de campos
+	fieldNames []string        // campos usados em fieldSel
+	include    map[string]bool // nil = todos os kinds
+	exclude    map[string]bool
+	name       *regexp.Regexp
+}
+
+// ValidateFilters verifica os filtros recebidos pela API (sintaxe do seletor,
+// regex e kinds conhecidos).
+func ValidateFilters(opts TopologyOptions) error {
+	_, err := compileFilter(opts)
+	return err
+}
+
+// namespaceList separa o filtro de namespace ("a,b") em nomes; "all" e vazio
+// retornam nil.
+func namespaceList(namespaceFilter string) []string {
+	if namespaceFilter == "" || namespaceFilter == "all" {
+		return nil
+	}
+	var out []string
+	for _, ns := range strings.Split(namespaceFilter, ",") {
+		if ns = strings.TrimSpace(ns); ns != "" {
+			out = append(out, ns)
+		}
+	}
+	return out
+}
+
+func compileFilter(opts TopologyOptions) (*resourceFilter, error) {
+	f := &resourceFilter{}
+
+	if nss := namespaceList(opts.Namespace); len(nss) > 1 {
+		f.namespaces = make(map[string]bool, len(nss))
+		for _, ns := range nss {
+			f.namespaces[ns] = true
+		}
+	}
+	if opts.Selector != "" {
+		sel, err := labels.Parse(opts.Selector)
+		if err != nil {
+			return nil, fmt.Errorf("selector inválido: %w", err)
+		}
+		f.selector = sel
+	}
+	if opts.FieldSelector != "" {
+		sel, err := fields.ParseSelector(opts.FieldSelector)
+		if err != nil {
+			return nil, fmt.Errorf("fieldSelector inválido: %w", err)
+		}
+		f.fieldSel = sel
+		for _, req := range sel.Requirements() {
+			f.fieldNames = append(f.fieldNames, req.Field)
+		}
+	}
+	if opts.NameRegex != "" {
+		re, err := regexp.Compile(opts.NameRegex)
+		if err != nil {
+			return nil, fmt.Errorf("regex de nome inválida: %w", err)
+		}
+		f.name = re
+	}
+
+	var err error
+	if f.include, err = kindSet(opts.Kinds); err != nil {
+		return nil, err
+	}
+	if f.exclude, err = kindSet(opts.ExcludeKinds); err != nil {
+		return nil, err
+	}
+	return f, nil
+}
+
+// kindSet normaliza os kinds (sem diferenciar maiúsculas) para os nomes do grafo.
+func kindSet(kinds []string) (map[string]bool, error) {
+	if len(kinds) == 0 {
+		return nil, nil
+	}
+	set := make(map[string]bool, len(kinds))
+	for _, k := range kinds {
+		known, err := canonicalKind(k)
+		if err != nil {
+			return nil, err
+		}
+		set[known] = true
+	}
+	return set, nil
+}
+
+// canonicalKind devolve o nome do kind usado nos nós do grafo.
+func canonicalKind(kind string) (string, error) {
+	for _, known := range filterableKinds {
+		if strings.EqualFold(kind, known) {
+			return known, nil
+		}
+	}
+	return "", fmt.Errorf("kind desconhecido: %s (disponíveis: %s)", kind, strings.Join(filterableKinds, ", "))
+}
+
+func (f *resourceFilter) kindAllowed(kind string) bool {
+	if f.include != nil && !f.include[kind] {
+		return false
+	}
+	return !f.exclude[kind]
+}
+
+// matches aplica seletor de labels, regex de nome e seletor de campos a um
+// objeto namespaced. O seletor de campos só vale para os kinds que expõem
+// todos os campos pedidos (como na API, metadata.name/namespace existem em todos).
+func (f *resourceFilter) matches(obj metav1.Object, extra fields.Set) bool {
+	if f.namespaces != nil && !f.namespaces[obj.GetNamespace()] {
+		return false
+	}
+	if f.selector != nil && !f.selector.Matches(labels.Set(obj.GetLabels())) {
+		return false
+	}
+	if f.name != nil && !f.name.MatchString(obj.GetName()) {
+		return false
+	}
+	if f.fieldSel != nil {
+		set := fields.Set{"metadata.name": obj.GetName(), "metadata.namespace": obj.GetNamespace()}
+		for k, v := range extra {
+			set[k] = v
+		}
+		for _, name := range f.fieldNames {
+			if _, ok := set[name]; !ok {
+				return true
+			}
+		}
+		return f.fieldSel.Matches(set)
+	}
+	return true
+}
+
+// filterObjects mantém os objetos do kind que passam no filtro; fieldsOf
+// (opcional) fornece os campos específicos do kind para o seletor de campos.
+func filterObjects[T metav1.Object](f *resourceFilter, kind string, items []T, fieldsOf func(T) fields.Set) []T {
+	if !f.kindAllowed(kind) {
+		return nil
+	}
+	out := items[:0:0]
+	for _, obj := range items {
+		var extra fields.Set
+		if fieldsOf != nil {
+			extra = fieldsOf(obj)
+		}
+		if f.matches(obj, extra) {
+			out = append(out, obj)
+		}
+	}
+	return out
+}
+
+// podFields são os campos de Pod suportados pelo field selector da API.
+func podFields(p *corev1.Pod) fields.Set {
+	return fields.Set{
+		"spec.nodeName":            p.Spec.NodeName,
+		"spec.restartPolicy":       string(p.Spec.RestartPolicy),
+		"spec.schedulerName":       p.Spec.SchedulerName,
+		"spec.serviceAccountName":  p.Spec.ServiceAccountName,
+		"status.phase":             string(p.Status.Phase),
+		"status.podIP":             p.Status.PodIP,
+		"status.nominatedNodeName": p.Status.NominatedNodeName,
+	}
+}
+
+// apply recorta res in-place. Recursos auxiliares (EndpointSlices,
+// NetworkPolicies, Namespaces) só são recortados pela lista de namespaces:
+// não viram nós, mas alimentam arestas e análises dos que ficaram.
+func (f *resourceFilter) apply(res *clusterResources) {
+	res.Deployments = filterObjects(f, "Deployment", res.Deployments, nil)
+	res.StatefulSets = filterObjects(f, "StatefulSet", res.StatefulSets, nil)
+	res.DaemonSets = filterObjects(f, "DaemonSet", res.DaemonSets, nil)
+	res.ReplicaSets = filterObjects(f, "ReplicaSet", res.ReplicaSets, nil)
+	res.Pods = filterObjects(f, "Pod", res.Pods, podFields)
+	res.Services = filterObjects(f, "Service", res.Services, nil)
+	res.HPAs = filterObjects(f, "HPA", res.HPAs, nil)
+	res.Jobs = filterObjects(f, "Job", res.Jobs, nil)
+	res.CronJobs = filterObjects(f, "CronJob", res.CronJobs, nil)
+	res.Ingresses = filterObjects(f, "Ingress", res.Ingresses, nil)
+	res.Gateways = filterObjects(f, "Gateway", res.Gateways, nil)
+	res.HTTPRoutes = filterObjects(f, "HTTPRoute", res.HTTPRoutes, nil)
+	res.PVCs = filterObjects(f, "PersistentVolumeClaim", res.PVCs, nil)
+	// Kinds genéricos não estão em filterableKinds: saem com qualquer filtro kinds=
+	if f.include != nil {
+		res.Generic = nil
+	}
+	res.Generic = keepObjects(res.Generic, func(obj metav1.Object) bool { return f.matches(obj, nil) })
+
+	if !f.kindAllowed("ConfigMap") {
+		res.ConfigMaps = nil
+	}
+	if !f.kindAllowed("Secret") {
+		res.Secrets = nil
+	}
+	if !f.kindAllowed("Node") {
+		res.Nodes = nil
+	}
+	if !f.kindAllowed("PersistentVolume") {
+		res.PVs = nil
+	}
+	if !f.kindAllowed("StorageClass") {
+		res.StorageClasses = nil
+	}
+
+	if f.namespaces != nil {
+		res.namespaces = f.namespaces
+		inScope := func(obj metav1.Object) bool { return f.namespaces[obj.GetNamespace()] }
+		res.ConfigMaps = keepObjects(res.ConfigMaps, inScope)
+		res.Secrets = keepObjects(res.Secrets, inScope)
+		res.EndpointSlices = keepObjects(res.EndpointSlices, inScope)
+		res.NetworkPolicies = keepObjects(res.NetworkPolicies, inScope)
+		res.Namespaces = keepObjects(res.Namespaces, func(ns metav1.Object) bool { return f.namespaces[ns.GetName()] })
+	}
+
+	// Com filtros de objeto, namespaces que ficaram vazios não viram grupo no grafo
+	if f.narrows() {
+		used := map[string]bool{}
+		mark := func(objs ...[]metav1.Object) {
+			for _, list := range objs {
+				for _, obj := range list {
+					used[obj.GetNamespace()] = true
+				}
+			}
+		}
+		mark(asObjects(res.Deployments), asObjects(res.StatefulSets), asObjects(res.DaemonSets),
+			asObjects(res.ReplicaSets), asObjects(res.Pods), asObjects(res.Services), asObjects(res.HPAs),
+			asObjects(res.Jobs), asObjects(res.CronJobs), asObjects(res.Ingresses), asObjects(res.Gateways),
+			asObjects(res.HTTPRoutes), asObjects(res.PVCs), asObjects(res.Generic))
+		res.Namespaces = keepObjects(res.Namespaces, func(ns metav1.Object) bool { return used[ns.GetName()] })
+	}
+}
+
+// narrows indica se há filtro além de namespace (seletores, nome ou kinds).
+func (f *resourceFilter) narrows() bool {
+	return f.selector != nil || f.fieldSel != nil || f.name != nil || f.include != nil || f.exclude != nil
+}
+
+func asObjects[T metav1.Object](items []T) []metav1.Object {
+	out := make([]metav1.Object, len(items))
+	for i, obj := range items {
+		out[i] = obj
+	}
+	return out
+}
+
+func keepObjects[T metav1.Object](items []T, keep func(metav1.Object) bool) []T {
+	out := items[:0:0]
+	for _, obj := range items {
+		if keep(obj) {
+			out = append(out, obj)
+		}
+	}
+	return out
+}
+
+// filterResources compila os filtros de opts e recorta res.
+func filterResources(res *clusterResources, opts TopologyOptions) error {
+	f, err := compileFilter(opts)
+	if err != nil {
+		return err
+	}
+	f.apply(res)
+	return nil
+}
+
+// dropDanglingEdges remove as arestas cuja origem ou destino não virou nó:
+// objeto filtrado ou referência por nome a algo que não existe.
+func dropDanglingEdges(g *ClusterGraph) {
+	exists := make(map[string]bool, len(g.Nodes))
+	for _, n := range g.Nodes {
+		exists[n.ID] = true
+	}
+	edges := g.Edges[:0]
+	for _, e := range g.Edges {
+		if exists[e.Source] && exists[e.Target] {
+			edges = append(edges, e)
+		}
+	}
+	g.Edges = edges
+}
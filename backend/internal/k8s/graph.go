@@ -1,16 +1,12 @@
 package k8s
 
 import (
-	"context"
-	"log"
-	"sync"
-	"time"
+	"errors"
+	"sort"
 
 	appsv1 "k8s.io/api/apps/v1"
-	autoscalingv2 "k8s.io/api/autoscaling/v2"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
-	"k8s.io/client-go/kubernetes"
 )
 
 /*
@@ -20,22 +16,56 @@ import (
 */
 
 type GraphNode struct {
-	ID        string            `json:"id"`
-	Kind      string            `json:"kind"`
-	Name      string            `json:"name"`
-	Namespace string            `json:"namespace,omitempty"`
-	Labels    map[string]string `json:"labels,omitempty"`
+	ID        string                 `json:"id"`
+	Kind      string                 `json:"kind"`
+	Name      string                 `json:"name"`
+	Namespace string                 `json:"namespace,omitempty"`
+	Labels    map[string]string      `json:"labels,omitempty"`
+	Data      map[string]interface{} `json:"data,omitempty"`   // atributos específicos do kind
+	Parent    string                 `json:"parent,omitempty"` // ID do nó de grupo que contém este nó
+	Group     bool                   `json:"group,omitempty"`  // nó de agrupamento (zona, região...)
 }
 
+// Tipos de aresta (GraphEdge.Type)
+const (
+	EdgeOwns    = "owns"    // ownerReference (Deployment -> ReplicaSet -> Pod...)
+	EdgeSelects = "selects" // Service -> Pod
+	EdgeScales  = "scales"  // HPA -> workload
+	EdgeRoutes  = "routes"  // Ingress/Gateway/HTTPRoute -> Service
+
+	EdgeMounts    = "mounts"    // Pod -> PVC
+	EdgeBinds     = "binds"     // PVC -> PV
+	EdgeUsesClass = "class"     // PV/PVC -> StorageClass
+	EdgeTemplates = "templates" // StatefulSet -> PVC (volumeClaimTemplates)
+
+	EdgeUsesConfig = "config" // Pod/workload -> ConfigMap/Secret
+
+	EdgeScheduledOn = "scheduled" // Pod -> Node
+
+	EdgeAllowedIngress = "allowed-ingress" // workload -> workload com ingress isolado que aceita o tráfego
+
+	EdgeDependsOn = "depends-on" // dependência declarada por anotação ou regra (ver edgerules.go)
+	EdgeCalls     = "calls"      // workload -> Service inferido de env vars/ConfigMaps (ver inferdeps.go)
+
+	EdgeMirrors = "mirror" // Service -> Service de mesmo namespace/nome em outro cluster (visão multi-cluster)
+)
+
+// ErrNotFound indica que o objeto pedido não existe no grafo/cache carregado.
+var ErrNotFound = errors.New("não encontrado")
+
 type GraphEdge struct {
-	ID     string `json:"id"`
-	Source string `json:"source"`
-	Target string `json:"target"`
+	ID     string                 `json:"id"`
+	Source string                 `json:"source"`
+	Target string                 `json:"target"`
+	Type   string                 `json:"type,omitempty"`
+	Label  string                 `json:"label,omitempty"`
+	Data   map[string]interface{} `json:"data,omitempty"` // ex: host/path de rotas
 }
 
 type ClusterGraph struct {
-	Nodes []GraphNode `json:"nodes"`
-	Edges []GraphEdge `json:"edges"`
+	Nodes    []GraphNode       `json:"nodes"`
+	Edges    []GraphEdge       `json:"edges"`
+	Warnings []TopologyWarning `json:"warnings"` // recursos que não puderam ser lidos
 }
 
 /*
@@ -45,21 +75,27 @@ type ClusterGraph struct {
 */
 
 type RFNode struct {
-	ID       string                 `json:"id"`
-	Type     string                 `json:"type,omitempty"`
-	Position map[string]float64     `json:"position"`
-	Data     map[string]interface{} `json:"data"`
+	ID         string                 `json:"id"`
+	Type       string                 `json:"type,omitempty"`
+	Position   map[string]float64     `json:"position"`
+	Data       map[string]interface{} `json:"data"`
+	ParentNode string                 `json:"parentNode,omitempty"` // posição relativa ao pai (React Flow v11)
+	Style      map[string]interface{} `json:"style,omitempty"`
 }
 
 type RFEdge struct {
-	ID     string `json:"id"`
-	Source string `json:"source"`
-	Target string `json:"target"`
+	ID     string                 `json:"id"`
+	Source string                 `json:"source"`
+	Target string                 `json:"target"`
+	Label  string                 `json:"label,omitempty"`
+	Data   map[string]interface{} `json:"data,omitempty"`
 }
 
 type RFGraph struct {
-	Nodes []RFNode `json:"nodes"`
-	Edges []RFEdge `json:"edges"`
+	Revision uint64            `json:"revision,omitempty"` // só em grafos servidos pelo cache
+	Nodes    []RFNode          `json:"nodes"`
+	Edges    []RFEdge          `json:"edges"`
+	Warnings []TopologyWarning `json:"warnings"`
 }
 
 /*
@@ -68,296 +104,288 @@ type RFGraph struct {
 ========================
 */
 
-func BuildTopologyGraph(
-	ctx context.Context,
-	client *kubernetes.Clientset,
-	namespaceFilter string,
-) (*RFGraph, error) {
+// TopologyOptions reúne os parâmetros de construção do grafo recebidos pela API.
+type TopologyOptions struct {
+	Namespace string // "all" ou vazio = todos os namespaces; "a,b" = lista
+	Layout    string // ver Layout* em layout.go; vazio = LayoutLayered
+
+	// ConfigEdges define a origem das arestas para ConfigMaps/Secrets
+	// (ConfigEdgesWorkload ou ConfigEdgesPod; vazio = workload)
+	ConfigEdges string
+
+	// NetworkPolicies ativa a análise de NetworkPolicy: status de isolamento
+	// por pod e arestas allowed-ingress entre workloads (opt-in, custo O(W²))
+	NetworkPolicies bool
+
+	// Filtros aplicados aos recursos antes da construção das arestas (ver filters.go)
+	Selector      string   `json:",omitempty"` // seletor de labels, ex: "app in (a,b),tier!=db"
+	FieldSelector string   `json:",omitempty"` // ex: "status.phase=Running,spec.nodeName=node-1"
+	Kinds         []string `json:",omitempty"` // só estes kinds (vazio = todos)
+	ExcludeKinds  []string `json:",omitempty"`
+	NameRegex     string   `json:",omitempty"`
+
+	// Detail é o nível de agregação (ver Detail* em aggregate.go; vazio = pod).
+	// Fora do nível pod, ReplicaSets históricos (0 réplicas) somem, a menos que ShowHistory.
+	Detail      string `json:",omitempty"`
+	ShowHistory bool   `json:",omitempty"`
+
+	// InferCalls infere arestas calls workload -> Service das env vars e ConfigMaps (ver inferdeps.go).
+	InferCalls bool `json:",omitempty"`
+
+	// OwnerRefs inclui os recursos configurados no cluster (CRDs) e resolve todas
+	// as ownerReferences por UID (ver ownerrefs.go).
+	OwnerRefs bool `json:",omitempty"`
+}
 
+// buildClusterGraph transforma os recursos brutos em nós e arestas.
+func buildClusterGraph(res *clusterResources, opts TopologyOptions) *ClusterGraph {
 	g := &ClusterGraph{
-		Nodes: []GraphNode{},
-		Edges: []GraphEdge{},
-	}
-
-	// Timeout de segurança
-	timeoutCtx, cancel := context.WithTimeout(ctx, 45*time.Second)
-	defer cancel()
-
-	var wg sync.WaitGroup
-	var mu sync.Mutex // Protege a escrita no grafo
-
-	// Estruturas para armazenar os dados brutos (usado para criar as arestas depois)
-	var (
-		allDeps *appsv1.DeploymentList
-		allSts  *appsv1.StatefulSetList
-		allDs   *appsv1.DaemonSetList
-		allRs   *appsv1.ReplicaSetList
-		allPods *corev1.PodList
-		allSvcs *corev1.ServiceList
-		allHpas *autoscalingv2.HorizontalPodAutoscalerList
-	)
-
-	// Função auxiliar para tratamento de erro e filtro
-	listOpts := metav1.ListOptions{}
-	// Se tiver filtro de namespace específico, usamos ele. Se for "all" ou vazio, pegamos tudo ("").
-	targetNS := ""
-	if namespaceFilter != "all" && namespaceFilter != "" {
-		targetNS = namespaceFilter
+		Nodes:    []GraphNode{},
+		Edges:    []GraphEdge{},
+		Warnings: append([]TopologyWarning{}, res.Warnings...),
 	}
+	hideHistoricalReplicaSets(res, opts)
 
 	// ---------------------------------------------------------
-	// 1. BUSCA PARALELA DE TODOS OS RECURSOS (CLUSTER-WIDE)
+	// 1. NÓS
 	// ---------------------------------------------------------
-	// Isso faz apenas ~7 chamadas no total ao invés de N_namespaces * 7
-	
-	wg.Add(7)
-
-	go func() {
-		defer wg.Done()
-		list, err := client.AppsV1().Deployments(targetNS).List(timeoutCtx, listOpts)
-		if err == nil {
-			mu.Lock()
-			allDeps = list
-			for _, d := range list.Items {
-				g.Nodes = append(g.Nodes, GraphNode{ID: "deploy:" + d.Namespace + ":" + d.Name, Kind: "Deployment", Name: d.Name, Namespace: d.Namespace, Labels: d.Labels})
-			}
-			mu.Unlock()
-		}
-	}()
-
-	go func() {
-		defer wg.Done()
-		list, err := client.AppsV1().StatefulSets(targetNS).List(timeoutCtx, listOpts)
-		if err == nil {
-			mu.Lock()
-			allSts = list
-			for _, s := range list.Items {
-				g.Nodes = append(g.Nodes, GraphNode{ID: "sts:" + s.Namespace + ":" + s.Name, Kind: "StatefulSet", Name: s.Name, Namespace: s.Namespace, Labels: s.Labels})
-			}
-			mu.Unlock()
-		}
-	}()
-
-	go func() {
-		defer wg.Done()
-		list, err := client.AppsV1().DaemonSets(targetNS).List(timeoutCtx, listOpts)
-		if err == nil {
-			mu.Lock()
-			allDs = list
-			for _, d := range list.Items {
-				g.Nodes = append(g.Nodes, GraphNode{ID: "ds:" + d.Namespace + ":" + d.Name, Kind: "DaemonSet", Name: d.Name, Namespace: d.Namespace, Labels: d.Labels})
-			}
-			mu.Unlock()
-		}
-	}()
-
-	go func() {
-		defer wg.Done()
-		list, err := client.AppsV1().ReplicaSets(targetNS).List(timeoutCtx, listOpts)
-		if err == nil {
-			mu.Lock()
-			allRs = list
-			for _, rs := range list.Items {
-				g.Nodes = append(g.Nodes, GraphNode{ID: "rs:" + rs.Namespace + ":" + rs.Name, Kind: "ReplicaSet", Name: rs.Name, Namespace: rs.Namespace, Labels: rs.Labels})
-			}
-			mu.Unlock()
-		}
-	}()
-
-	go func() {
-		defer wg.Done()
-		list, err := client.CoreV1().Pods(targetNS).List(timeoutCtx, listOpts)
-		if err == nil {
-			mu.Lock()
-			allPods = list
-			for _, p := range list.Items {
-				g.Nodes = append(g.Nodes, GraphNode{ID: "pod:" + p.Namespace + ":" + p.Name, Kind: "Pod", Name: p.Name, Namespace: p.Namespace, Labels: p.Labels})
-			}
-			mu.Unlock()
-		}
-	}()
-
-	go func() {
-		defer wg.Done()
-		list, err := client.CoreV1().Services(targetNS).List(timeoutCtx, listOpts)
-		if err == nil {
-			mu.Lock()
-			allSvcs = list
-			for _, s := range list.Items {
-				g.Nodes = append(g.Nodes, GraphNode{ID: "svc:" + s.Namespace + ":" + s.Name, Kind: "Service", Name: s.Name, Namespace: s.Namespace, Labels: s.Labels})
-			}
-			mu.Unlock()
-		}
-	}()
-
-	go func() {
-		defer wg.Done()
-		list, err := client.AutoscalingV2().HorizontalPodAutoscalers(targetNS).List(timeoutCtx, listOpts)
-		if err == nil {
-			mu.Lock()
-			allHpas = list
-			for _, h := range list.Items {
-				g.Nodes = append(g.Nodes, GraphNode{ID: "hpa:" + h.Namespace + ":" + h.Name, Kind: "HPA", Name: h.Name, Namespace: h.Namespace, Labels: h.Labels})
-			}
-			mu.Unlock()
-		}
-	}()
-
-	// Nodes físicos e Namespaces (rápido)
-	go func() {
-		nodes, _ := client.CoreV1().Nodes().List(timeoutCtx, metav1.ListOptions{})
-		if nodes != nil {
-			mu.Lock()
-			for _, n := range nodes.Items {
-				g.Nodes = append(g.Nodes, GraphNode{ID: "node:" + n.Name, Kind: "Node", Name: n.Name, Labels: n.Labels})
-			}
-			mu.Unlock()
-		}
-	}()
-
-	wg.Wait()
+	for _, d := range res.Deployments {
+		g.Nodes = append(g.Nodes, GraphNode{ID: "deploy:" + d.Namespace + ":" + d.Name, Kind: "Deployment", Name: d.Name, Namespace: d.Namespace, Labels: d.Labels})
+	}
+	for _, s := range res.StatefulSets {
+		g.Nodes = append(g.Nodes, GraphNode{ID: "sts:" + s.Namespace + ":" + s.Name, Kind: "StatefulSet", Name: s.Name, Namespace: s.Namespace, Labels: s.Labels})
+	}
+	for _, d := range res.DaemonSets {
+		g.Nodes = append(g.Nodes, GraphNode{ID: "ds:" + d.Namespace + ":" + d.Name, Kind: "DaemonSet", Name: d.Name, Namespace: d.Namespace, Labels: d.Labels})
+	}
+	for _, rs := range res.ReplicaSets {
+		g.Nodes = append(g.Nodes, GraphNode{ID: "rs:" + rs.Namespace + ":" + rs.Name, Kind: "ReplicaSet", Name: rs.Name, Namespace: rs.Namespace, Labels: rs.Labels})
+	}
+	for _, p := range res.Pods {
+		g.Nodes = append(g.Nodes, GraphNode{ID: "pod:" + p.Namespace + ":" + p.Name, Kind: "Pod", Name: p.Name, Namespace: p.Namespace, Labels: p.Labels})
+	}
+	for _, s := range res.Services {
+		g.Nodes = append(g.Nodes, GraphNode{ID: "svc:" + s.Namespace + ":" + s.Name, Kind: "Service", Name: s.Name, Namespace: s.Namespace, Labels: s.Labels})
+	}
+	for _, h := range res.HPAs {
+		g.Nodes = append(g.Nodes, GraphNode{ID: "hpa:" + h.Namespace + ":" + h.Name, Kind: "HPA", Name: h.Name, Namespace: h.Namespace, Labels: h.Labels})
+	}
+	for _, n := range res.Nodes {
+		g.Nodes = append(g.Nodes, GraphNode{ID: "node:" + n.Name, Kind: "Node", Name: n.Name, Labels: n.Labels})
+	}
 
 	// ---------------------------------------------------------
 	// 2. CONSTRUÇÃO DE ARESTAS (EDGES)
 	// ---------------------------------------------------------
-	// Agora que temos tudo em memória, processamos as conexões.
 	// Precisamos iterar por namespace para não conectar recursos de namespaces diferentes.
 
 	// Agrupa recursos por namespace em mapas para acesso rápido
 	// Isso evita loops aninhados gigantescos O(N^2) global
-	podsByNs := make(map[string][]corev1.Pod)
-	if allPods != nil {
-		for _, p := range allPods.Items {
-			podsByNs[p.Namespace] = append(podsByNs[p.Namespace], p)
-		}
+	podsByNs := make(map[string][]*corev1.Pod)
+	for _, p := range res.Pods {
+		podsByNs[p.Namespace] = append(podsByNs[p.Namespace], p)
 	}
 
-	rsByNs := make(map[string][]appsv1.ReplicaSet)
-	if allRs != nil {
-		for _, rs := range allRs.Items {
-			rsByNs[rs.Namespace] = append(rsByNs[rs.Namespace], rs)
-		}
+	rsByNs := make(map[string][]*appsv1.ReplicaSet)
+	for _, rs := range res.ReplicaSets {
+		rsByNs[rs.Namespace] = append(rsByNs[rs.Namespace], rs)
 	}
 
 	// Processa Edges
-	if allSvcs != nil {
-		for _, svc := range allSvcs.Items {
-			nsPods := podsByNs[svc.Namespace]
-			for _, pod := range nsPods {
-				if podMatchesSelector(pod.Labels, svc.Spec.Selector) {
-					g.Edges = append(g.Edges, GraphEdge{
-						ID:     "edge:svc->pod:" + svc.Namespace + ":" + svc.Name + "->" + pod.Name,
-						Source: "svc:" + svc.Namespace + ":" + svc.Name,
-						Target: "pod:" + svc.Namespace + ":" + pod.Name,
-					})
-				}
-			}
-		}
-	}
+	addServiceEndpoints(g, res, podsByNs)
 
-	if allDeps != nil {
-		for _, dep := range allDeps.Items {
-			nsRs := rsByNs[dep.Namespace]
-			nsPods := podsByNs[dep.Namespace]
-			
-			for _, rs := range nsRs {
-				if ownerRefMatches(rs.OwnerReferences, "Deployment", dep.Name) {
-					g.Edges = append(g.Edges, GraphEdge{
-						ID:     "edge:deploy->rs:" + dep.Namespace + ":" + dep.Name + "->" + rs.Name,
-						Source: "deploy:" + dep.Namespace + ":" + dep.Name,
-						Target: "rs:" + dep.Namespace + ":" + rs.Name,
-					})
-					// RS -> Pod
-					for _, pod := range nsPods {
-						if ownerRefMatches(pod.OwnerReferences, "ReplicaSet", rs.Name) {
-							g.Edges = append(g.Edges, GraphEdge{
-								ID:     "edge:rs->pod:" + dep.Namespace + ":" + rs.Name + "->" + pod.Name,
-								Source: "rs:" + dep.Namespace + ":" + rs.Name,
-								Target: "pod:" + dep.Namespace + ":" + pod.Name,
-							})
-						}
+	for _, dep := range res.Deployments {
+		nsRs := rsByNs[dep.Namespace]
+		nsPods := podsByNs[dep.Namespace]
+
+		for _, rs := range nsRs {
+			if ownerRefMatches(rs.OwnerReferences, "Deployment", dep.Name) {
+				g.Edges = append(g.Edges, GraphEdge{
+					ID:     "edge:deploy->rs:" + dep.Namespace + ":" + dep.Name + "->" + rs.Name,
+					Source: "deploy:" + dep.Namespace + ":" + dep.Name,
+					Target: "rs:" + dep.Namespace + ":" + rs.Name,
+					Type:   EdgeOwns,
+				})
+				// RS -> Pod
+				for _, pod := range nsPods {
+					if ownerRefMatches(pod.OwnerReferences, "ReplicaSet", rs.Name) {
+						g.Edges = append(g.Edges, GraphEdge{
+							ID:     "edge:rs->pod:" + dep.Namespace + ":" + rs.Name + "->" + pod.Name,
+							Source: "rs:" + dep.Namespace + ":" + rs.Name,
+							Target: "pod:" + dep.Namespace + ":" + pod.Name,
+							Type:   EdgeOwns,
+						})
 					}
 				}
 			}
 		}
 	}
 
-	if allSts != nil {
-		for _, sts := range allSts.Items {
-			nsPods := podsByNs[sts.Namespace]
-			for _, pod := range nsPods {
-				if ownerRefMatches(pod.OwnerReferences, "StatefulSet", sts.Name) {
-					g.Edges = append(g.Edges, GraphEdge{
-						ID:     "edge:sts->pod:" + sts.Namespace + ":" + sts.Name + "->" + pod.Name,
-						Source: "sts:" + sts.Namespace + ":" + sts.Name,
-						Target: "pod:" + sts.Namespace + ":" + pod.Name,
-					})
-				}
+	for _, sts := range res.StatefulSets {
+		nsPods := podsByNs[sts.Namespace]
+		for _, pod := range nsPods {
+			if ownerRefMatches(pod.OwnerReferences, "StatefulSet", sts.Name) {
+				g.Edges = append(g.Edges, GraphEdge{
+					ID:     "edge:sts->pod:" + sts.Namespace + ":" + sts.Name + "->" + pod.Name,
+					Source: "sts:" + sts.Namespace + ":" + sts.Name,
+					Target: "pod:" + sts.Namespace + ":" + pod.Name,
+					Type:   EdgeOwns,
+				})
 			}
 		}
 	}
 
-	if allDs != nil {
-		for _, ds := range allDs.Items {
-			nsPods := podsByNs[ds.Namespace]
-			for _, pod := range nsPods {
-				if ownerRefMatches(pod.OwnerReferences, "DaemonSet", ds.Name) {
-					g.Edges = append(g.Edges, GraphEdge{
-						ID:     "edge:ds->pod:" + ds.Namespace + ":" + ds.Name + "->" + pod.Name,
-						Source: "ds:" + ds.Namespace + ":" + ds.Name,
-						Target: "pod:" + ds.Namespace + ":" + pod.Name,
-					})
-				}
+	for _, ds := range res.DaemonSets {
+		nsPods := podsByNs[ds.Namespace]
+		for _, pod := range nsPods {
+			if ownerRefMatches(pod.OwnerReferences, "DaemonSet", ds.Name) {
+				g.Edges = append(g.Edges, GraphEdge{
+					ID:     "edge:ds->pod:" + ds.Namespace + ":" + ds.Name + "->" + pod.Name,
+					Source: "ds:" + ds.Namespace + ":" + ds.Name,
+					Target: "pod:" + ds.Namespace + ":" + pod.Name,
+					Type:   EdgeOwns,
+				})
 			}
 		}
 	}
 
-	if allHpas != nil {
-		for _, h := range allHpas.Items {
-			ref := h.Spec.ScaleTargetRef
-			ns := h.Namespace
-			if ref.Kind == "Deployment" {
-				g.Edges = append(g.Edges, GraphEdge{
-					ID:     "edge:hpa->deploy:" + ns + ":" + h.Name + "->" + ref.Name,
-					Source: "hpa:" + ns + ":" + h.Name,
-					Target: "deploy:" + ns + ":" + ref.Name,
-				})
-			} else if ref.Kind == "StatefulSet" {
-				g.Edges = append(g.Edges, GraphEdge{
-					ID:     "edge:hpa->sts:" + ns + ":" + h.Name + "->" + ref.Name,
-					Source: "hpa:" + ns + ":" + h.Name,
-					Target: "sts:" + ns + ":" + ref.Name,
-				})
-			}
+	for _, h := range res.HPAs {
+		ref := h.Spec.ScaleTargetRef
+		ns := h.Namespace
+		if ref.Kind == "Deployment" {
+			g.Edges = append(g.Edges, GraphEdge{
+				ID:     "edge:hpa->deploy:" + ns + ":" + h.Name + "->" + ref.Name,
+				Source: "hpa:" + ns + ":" + h.Name,
+				Target: "deploy:" + ns + ":" + ref.Name,
+				Type:   EdgeScales,
+			})
+		} else if ref.Kind == "StatefulSet" {
+			g.Edges = append(g.Edges, GraphEdge{
+				ID:     "edge:hpa->sts:" + ns + ":" + h.Name + "->" + ref.Name,
+				Source: "hpa:" + ns + ":" + h.Name,
+				Target: "sts:" + ns + ":" + ref.Name,
+				Type:   EdgeScales,
+			})
 		}
 	}
 
-	log.Printf("[TOPOLOGY] Completed. Nodes: %d, Edges: %d", len(g.Nodes), len(g.Edges))
+	addBatchGraph(g, res)
+	addRoutingGraph(g, res)
+	addStorageGraph(g, res)
+	addConfigGraph(g, res, opts.ConfigEdges)
+	if opts.OwnerRefs {
+		addOwnerRefGraph(g, res)
+	}
+	addDeclaredEdges(g, res)
+	if opts.InferCalls {
+		addInferredCalls(g, res)
+	}
+	addSchedulingGraph(g, res)
+	addHealthData(g, res)
+	if opts.NetworkPolicies {
+		addNetworkPolicyGraph(g, res)
+	}
+	dropDanglingEdges(g)
+	aggregateGraph(g, opts.Detail)
+	addNamespaceGroups(g, res)
+
+	return g
+}
+
+// toRFGraph converte o grafo de domínio para o formato do React Flow, já com
+// as posições calculadas pelo layout escolhido. Nós com pai recebem posição
+// relativa ao pai e vêm depois dele na lista, como o React Flow exige.
+func toRFGraph(g *ClusterGraph, layoutName string) *RFGraph {
+	var layout *Layout
+	if layoutName != LayoutNone {
+		layout = ComputeLayout(g, layoutName)
+	}
+
+	exists := make(map[string]bool, len(g.Nodes))
+	for _, n := range g.Nodes {
+		exists[n.ID] = true
+	}
 
-	// Conversão para React Flow
 	rfNodes := []RFNode{}
 	x, y := 0.0, 0.0
-	for _, n := range g.Nodes {
-		rfNodes = append(rfNodes, RFNode{
-			ID:       n.ID,
-			Type:     "default",
-			Position: map[string]float64{"x": x, "y": y},
-			Data: map[string]interface{}{
-				"label":     n.Kind + ": " + n.Name,
-				"namespace": n.Namespace, // Essencial
-				"kind":      n.Kind,
-				"labels":    n.Labels,
-			},
-		})
-		y += 10
+	for _, n := range nodesParentsFirst(g.Nodes) {
+		parent := ""
+		if n.Parent != "" && exists[n.Parent] {
+			parent = n.Parent
+		}
+		if layout != nil {
+			p := layout.Positions[n.ID]
+			x, y = p.X, p.Y
+			if parent != "" {
+				pp := layout.Positions[parent]
+				x, y = p.X-pp.X, p.Y-pp.Y
+			}
+		}
+		data := map[string]interface{}{}
+		for k, v := range n.Data {
+			data[k] = v
+		}
+		data["label"] = n.Kind + ": " + n.Name
+		data["namespace"] = n.Namespace // Essencial
+		data["kind"] = n.Kind
+		data["labels"] = n.Labels
+		rfNode := RFNode{
+			ID:         n.ID,
+			Type:       "default",
+			Position:   map[string]float64{"x": x, "y": y},
+			Data:       data,
+			ParentNode: parent,
+		}
+		if n.Group {
+			rfNode.Type = "group"
+			data["isGroup"] = true
+		}
+		if layout != nil {
+			if r, ok := layout.Containers[n.ID]; ok {
+				rfNode.Style = map[string]interface{}{"width": r.Width, "height": r.Height}
+			}
+		}
+		rfNodes = append(rfNodes, rfNode)
+		if layout == nil {
+			y += 10
+		}
 	}
 
 	rfEdges := []RFEdge{}
 	for _, e := range g.Edges {
-		rfEdges = append(rfEdges, RFEdge{ID: e.ID, Source: e.Source, Target: e.Target})
+		data := map[string]interface{}{"type": e.Type}
+		for k, v := range e.Data {
+			data[k] = v
+		}
+		rfEdges = append(rfEdges, RFEdge{ID: e.ID, Source: e.Source, Target: e.Target, Label: e.Label, Data: data})
+	}
+
+	return &RFGraph{Nodes: rfNodes, Edges: rfEdges, Warnings: g.Warnings}
+}
+
+// nodesParentsFirst ordena os nós por profundidade na hierarquia de grupos,
+// mantendo a ordem original entre nós da mesma profundidade.
+func nodesParentsFirst(nodes []GraphNode) []GraphNode {
+	parentOf := make(map[string]string, len(nodes))
+	for _, n := range nodes {
+		parentOf[n.ID] = n.Parent
+	}
+	depth := func(id string) int {
+		d := 0
+		for p := parentOf[id]; p != "" && d < len(nodes); p = parentOf[p] {
+			d++
+		}
+		return d
 	}
 
-	return &RFGraph{Nodes: rfNodes, Edges: rfEdges}, nil
+	out := make([]GraphNode, len(nodes))
+	copy(out, nodes)
+	depths := make(map[string]int, len(nodes))
+	for _, n := range out {
+		depths[n.ID] = depth(n.ID)
+	}
+	sort.SliceStable(out, func(i, j int) bool { return depths[out[i].ID] < depths[out[j].ID] })
+	return out
 }
 
 // Helpers
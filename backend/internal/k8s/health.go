@@ -0,0 +1,413 @@
+package k8s
+
+import (
+	appsv1 "k8s.io/api/apps/v1"
+	autoscalingv2 "k8s.io/api/autoscaling/v2"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+)
+
+/*
+========================
+ SAÚDE DOS NÓS (HEALTH)
+========================
+*/
+
+// Valores normalizados de data.health, usados pela UI para colorir os nós.
+const (
+	HealthHealthy  = "healthy"
+	HealthDegraded = "degraded"
+	HealthFailed   = "failed"
+	HealthUnknown  = "unknown"
+)
+
+// healthRank ordena da melhor para a pior; unknown fica abaixo de degraded
+// para não mascarar um problema conhecido.
+var healthRank = map[string]int{
+	HealthHealthy:  0,
+	HealthUnknown:  1,
+	HealthDegraded: 2,
+	HealthFailed:   3,
+}
+
+func worstHealth(a, b string) string {
+	if healthRank[b] > healthRank[a] {
+		return b
+	}
+	return a
+}
+
+// Motivos de espera/término de container que indicam falha do pod.
+var failedContainerReasons = map[string]bool{
+	"CrashLoopBackOff":           true,
+	"ImagePullBackOff":           true,
+	"ErrImagePull":               true,
+	"InvalidImageName":           true,
+	"CreateContainerConfigError": true,
+	"CreateContainerError":       true,
+	"RunContainerError":          true,
+}
+
+// addHealthData calcula data.health de pods, workloads, HPAs, Services e Nodes
+// e propaga para os donos (arestas owns) a pior saúde dos filhos. O valor
+// próprio de cada nó fica em data.selfHealth.
+func addHealthData(g *ClusterGraph, res *clusterResources) {
+	own := map[string]map[string]interface{}{}
+
+	for _, p := range res.Pods {
+		own["pod:"+p.Namespace+":"+p.Name] = podHealthData(p)
+	}
+	for _, d := range res.Deployments {
+		own["deploy:"+d.Namespace+":"+d.Name] = deploymentHealthData(d)
+	}
+	for _, s := range res.StatefulSets {
+		own["sts:"+s.Namespace+":"+s.Name] = statefulSetHealthData(s)
+	}
+	for _, d := range res.DaemonSets {
+		own["ds:"+d.Namespace+":"+d.Name] = daemonSetHealthData(d)
+	}
+	for _, rs := range res.ReplicaSets {
+		own["rs:"+rs.Namespace+":"+rs.Name] = replicaSetHealthData(rs)
+	}
+	for _, j := range res.Jobs {
+		own["job:"+j.Namespace+":"+j.Name] = map[string]interface{}{"health": jobHealth(j)}
+	}
+	for _, h := range res.HPAs {
+		own["hpa:"+h.Namespace+":"+h.Name] = hpaHealthData(h)
+	}
+	for _, n := range res.Nodes {
+		own["node:"+n.Name] = nodeHealthData(n)
+	}
+
+	index := make(map[string]int, len(g.Nodes))
+	for i, n := range g.Nodes {
+		index[n.ID] = i
+	}
+	for id, data := range own {
+		i, ok := index[id]
+		if !ok {
+			continue
+		}
+		if g.Nodes[i].Data == nil {
+			g.Nodes[i].Data = map[string]interface{}{}
+		}
+		for k, v := range data {
+			g.Nodes[i].Data[k] = v
+		}
+	}
+
+	// Services: sem endpoints prontos = failed; parte pronta = degraded
+	for i := range g.Nodes {
+		n := &g.Nodes[i]
+		if n.Kind != "Service" || n.Data == nil {
+			continue
+		}
+		total, _ := n.Data["endpoints"].(int)
+		ready, _ := n.Data["readyEndpoints"].(int)
+		switch {
+		case n.Data["noReadyEndpoints"] == true:
+			n.Data["health"] = HealthFailed
+		case ready < total:
+			n.Data["health"] = HealthDegraded
+		default:
+			n.Data["health"] = HealthHealthy
+		}
+	}
+
+	rollupHealth(g, index)
+}
+
+// rollupHealth propaga a pior saúde pelas arestas owns (CronJob -> Job -> Pod,
+// Deployment -> ReplicaSet -> Pod...). Pods já encerrados de um Job não entram:
+// tentativas que falharam e foram refeitas já estão refletidas no status do Job.
+func rollupHealth(g *ClusterGraph, index map[string]int) {
+	children := map[string][]string{}
+	for _, e := range g.Edges {
+		if e.Type == EdgeOwns {
+			children[e.Source] = append(children[e.Source], e.Target)
+		}
+	}
+
+	memo := map[string]string{}
+	visiting := map[string]bool{}
+	var resolve func(id string) string
+	resolve = func(id string) string {
+		if h, ok := memo[id]; ok {
+			return h
+		}
+		i, ok := index[id]
+		if !ok || visiting[id] {
+			return ""
+		}
+		visiting[id] = true
+		n := &g.Nodes[i]
+
+		self, _ := n.Data["health"].(string)
+		worst := self
+		for _, child := range children[id] {
+			if n.Kind == "Job" && podTerminated(g, index, child) {
+				continue
+			}
+			if h := resolve(child); h != "" {
+				worst = worstHealth(worst, h)
+			}
+		}
+		if worst != "" && len(children[id]) > 0 {
+			if n.Data == nil {
+				n.Data = map[string]interface{}{}
+			}
+			if self == "" {
+				self = HealthUnknown
+			}
+			n.Data["selfHealth"] = self
+			n.Data["health"] = worst
+		}
+		visiting[id] = false
+		memo[id] = worst
+		return worst
+	}
+	for id := range children {
+		resolve(id)
+	}
+}
+
+func podTerminated(g *ClusterGraph, index map[string]int, id string) bool {
+	i, ok := index[id]
+	if !ok || g.Nodes[i].Kind != "Pod" {
+		return false
+	}
+	phase, _ := g.Nodes[i].Data["phase"].(string)
+	return phase == string(corev1.PodSucceeded) || phase == string(corev1.PodFailed)
+}
+
+func podHealthData(p *corev1.Pod) map[string]interface{} {
+	ready, restarts := 0, int32(0)
+	reasons := []string{}
+	images := []string{}
+	failing := false
+
+	for _, cs := range p.Status.ContainerStatuses {
+		if cs.Ready {
+			ready++
+		}
+		restarts += cs.RestartCount
+		if w := cs.State.Waiting; w != nil && w.Reason != "" {
+			reasons = appendUnique(reasons, w.Reason)
+			failing = failing || failedContainerReasons[w.Reason]
+		}
+		if t := cs.State.Terminated; t != nil && t.Reason != "" && t.ExitCode != 0 {
+			reasons = appendUnique(reasons, t.Reason)
+		}
+		if t := cs.LastTerminationState.Terminated; t != nil && t.Reason == "OOMKilled" {
+			reasons = appendUnique(reasons, t.Reason)
+		}
+	}
+	for _, cs := range p.Status.InitContainerStatuses {
+		restarts += cs.RestartCount
+		if w := cs.State.Waiting; w != nil && w.Reason != "" && w.Reason != "PodInitializing" {
+			reasons = appendUnique(reasons, w.Reason)
+			failing = failing || failedContainerReasons[w.Reason]
+		}
+	}
+	for _, c := range p.Spec.Containers {
+		images = appendUnique(images, c.Image)
+	}
+
+	phase := p.Status.Phase
+	if phase == "" {
+		phase = corev1.PodUnknown
+	}
+	total := len(p.Spec.Containers)
+
+	health := HealthUnknown
+	switch {
+	case phase == corev1.PodFailed || failing:
+		health = HealthFailed
+	case phase == corev1.PodSucceeded:
+		health = HealthHealthy
+	case phase == corev1.PodRunning && ready == total:
+		health = HealthHealthy
+	case phase == corev1.PodRunning || phase == corev1.PodPending:
+		health = HealthDegraded
+	}
+	if p.Status.Reason == "Evicted" {
+		reasons = appendUnique(reasons, p.Status.Reason)
+	}
+
+	data := map[string]interface{}{
+		"health":          health,
+		"phase":           string(phase),
+		"readyContainers": ready,
+		"totalContainers": total,
+		"restarts":        restarts,
+		"images":          images,
+		"nodeName":        p.Spec.NodeName,
+	}
+	if len(reasons) > 0 {
+		data["reasons"] = reasons
+	}
+	return data
+}
+
+// replicaHealth é a regra comum a workloads com réplicas.
+func replicaHealth(desired, ready, updated int32) string {
+	switch {
+	case desired == 0:
+		return HealthHealthy
+	case ready == 0:
+		return HealthFailed
+	case ready < desired || updated < desired:
+		return HealthDegraded
+	}
+	return HealthHealthy
+}
+
+func desiredReplicas(r *int32) int32 {
+	if r == nil {
+		return 1
+	}
+	return *r
+}
+
+func templateImages(spec *corev1.PodSpec) []string {
+	images := []string{}
+	for _, c := range spec.Containers {
+		images = appendUnique(images, c.Image)
+	}
+	return images
+}
+
+func deploymentHealthData(d *appsv1.Deployment) map[string]interface{} {
+	desired := desiredReplicas(d.Spec.Replicas)
+	health := replicaHealth(desired, d.Status.ReadyReplicas, d.Status.UpdatedReplicas)
+	for _, c := range d.Status.Conditions {
+		if c.Type == appsv1.DeploymentProgressing && c.Reason == "ProgressDeadlineExceeded" {
+			health = HealthFailed
+		}
+	}
+	return map[string]interface{}{
+		"health":            health,
+		"desiredReplicas":   desired,
+		"readyReplicas":     d.Status.ReadyReplicas,
+		"updatedReplicas":   d.Status.UpdatedReplicas,
+		"availableReplicas": d.Status.AvailableReplicas,
+		"images":            templateImages(&d.Spec.Template.Spec),
+	}
+}
+
+func statefulSetHealthData(s *appsv1.StatefulSet) map[string]interface{} {
+	desired := desiredReplicas(s.Spec.Replicas)
+	return map[string]interface{}{
+		"health":          replicaHealth(desired, s.Status.ReadyReplicas, s.Status.UpdatedReplicas),
+		"desiredReplicas": desired,
+		"readyReplicas":   s.Status.ReadyReplicas,
+		"updatedReplicas": s.Status.UpdatedReplicas,
+		"currentReplicas": s.Status.CurrentReplicas,
+		"images":          templateImages(&s.Spec.Template.Spec),
+	}
+}
+
+func daemonSetHealthData(d *appsv1.DaemonSet) map[string]interface{} {
+	desired := d.Status.DesiredNumberScheduled
+	return map[string]interface{}{
+		"health":          replicaHealth(desired, d.Status.NumberReady, d.Status.UpdatedNumberScheduled),
+		"desiredReplicas": desired,
+		"readyReplicas":   d.Status.NumberReady,
+		"updatedReplicas": d.Status.UpdatedNumberScheduled,
+		"unavailable":     d.Status.NumberUnavailable,
+		"images":          templateImages(&d.Spec.Template.Spec),
+	}
+}
+
+func replicaSetHealthData(rs *appsv1.ReplicaSet) map[string]interface{} {
+	desired := desiredReplicas(rs.Spec.Replicas)
+	return map[string]interface{}{
+		"health":            replicaHealth(desired, rs.Status.ReadyReplicas, rs.Status.Replicas),
+		"desiredReplicas":   desired,
+		"readyReplicas":     rs.Status.ReadyReplicas,
+		"availableReplicas": rs.Status.AvailableReplicas,
+		"images":            templateImages(&rs.Spec.Template.Spec),
+	}
+}
+
+func jobHealth(j *batchv1.Job) string {
+	for _, c := range j.Status.Conditions {
+		if c.Status != corev1.ConditionTrue {
+			continue
+		}
+		switch c.Type {
+		case batchv1.JobFailed:
+			return HealthFailed
+		case batchv1.JobComplete:
+			return HealthHealthy
+		}
+	}
+	if j.Status.Failed > 0 {
+		return HealthDegraded // ainda dentro do backoffLimit
+	}
+	return HealthHealthy
+}
+
+func hpaHealthData(h *autoscalingv2.HorizontalPodAutoscaler) map[string]interface{} {
+	health := HealthHealthy
+	conditions := map[string]string{}
+	for _, c := range h.Status.Conditions {
+		conditions[string(c.Type)] = string(c.Status)
+		if (c.Type == autoscalingv2.AbleToScale || c.Type == autoscalingv2.ScalingActive) && c.Status == corev1.ConditionFalse {
+			health = HealthDegraded
+		}
+	}
+	data := map[string]interface{}{
+		"health":          health,
+		"currentReplicas": h.Status.CurrentReplicas,
+		"desiredReplicas": h.Status.DesiredReplicas,
+		"maxReplicas":     h.Spec.MaxReplicas,
+		"conditions":      conditions,
+	}
+	if h.Spec.MinReplicas != nil {
+		data["minReplicas"] = *h.Spec.MinReplicas
+	}
+	return data
+}
+
+// Condições de pressão do Node (True = problema).
+var nodePressureConditions = []corev1.NodeConditionType{
+	corev1.NodeMemoryPressure,
+	corev1.NodeDiskPressure,
+	corev1.NodePIDPressure,
+	corev1.NodeNetworkUnavailable,
+}
+
+func nodeHealthData(n *corev1.Node) map[string]interface{} {
+	ready := corev1.ConditionUnknown
+	pressure := []string{}
+	for _, c := range n.Status.Conditions {
+		if c.Type == corev1.NodeReady {
+			ready = c.Status
+			continue
+		}
+		for _, pc := range nodePressureConditions {
+			if c.Type == pc && c.Status == corev1.ConditionTrue {
+				pressure = append(pressure, string(c.Type))
+			}
+		}
+	}
+
+	health := HealthHealthy
+	switch {
+	case ready == corev1.ConditionFalse:
+		health = HealthFailed
+	case ready == corev1.ConditionUnknown:
+		health = HealthUnknown
+	case len(pressure) > 0 || n.Spec.Unschedulable:
+		health = HealthDegraded
+	}
+
+	return map[string]interface{}{
+		"health":        health,
+		"ready":         string(ready),
+		"pressure":      pressure,
+		"unschedulable": n.Spec.Unschedulable,
+		"kubelet":       n.Status.NodeInfo.KubeletVersion,
+	}
+}
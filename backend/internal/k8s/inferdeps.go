@@ -0,0 +1,269 @@
+package k8s
+
+import (
+	"regexp"
+	"sort"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/labels"
+)
+
+/*
+========================
+ DEPENDÊNCIAS INFERIDAS (ENV VARS E CONFIGMAPS)
+========================
+*/
+
+// Com TopologyOptions.InferCalls, os valores literais das env vars dos pods e
+// os dos ConfigMaps que eles referenciam são varridos em busca de Services do
+// cluster: nomes DNS (web.shop.svc.cluster.local), variáveis *_SERVICE_HOST,
+// ClusterIPs e URLs com o nome curto de um Service do mesmo namespace. Cada
+// achado vira uma aresta EdgeCalls workload -> Service com data.confidence
+// (a maior entre as evidências) e data.evidence. Secrets nunca são lidos e a
+// evidência guarda só o host encontrado, não o valor (URLs podem ter senha).
+// Services que selecionam o próprio pod não viram chamada (URL pública do app).
+
+// Regras de inferência (evidence.rule) e a confiança de cada uma.
+const (
+	inferRuleDNS         = "dns"          // <svc>.<ns>.svc[.cluster.local]
+	inferRuleServiceHost = "service-host" // <SVC>_SERVICE_HOST
+	inferRuleClusterIP   = "cluster-ip"   // valor igual ao ClusterIP de um Service
+	inferRuleShortName   = "short-name"   // http://<svc>:port ou <svc>:port no mesmo namespace
+)
+
+var inferConfidence = map[string]float64{
+	inferRuleDNS:         0.9,
+	inferRuleServiceHost: 0.8,
+	inferRuleClusterIP:   0.8,
+	inferRuleShortName:   0.5,
+}
+
+// configMapPenalty reduz a confiança de achados em ConfigMaps montados como
+// volume: o arquivo pode não ser lido pela aplicação.
+const configMapPenalty = 0.2
+
+var (
+	svcDNSRe       = regexp.MustCompile(`(?i)\b([a-z0-9](?:[-a-z0-9]*[a-z0-9])?)\.([a-z0-9](?:[-a-z0-9]*[a-z0-9])?)\.svc(?:\.cluster\.local)?\b`)
+	urlHostRe      = regexp.MustCompile(`(?i)[a-z][a-z0-9+.-]*://(?:[^@/\s]*@)?([a-z0-9](?:[-a-z0-9]*[a-z0-9])?)(?:[:/\s]|$)`)
+	hostPortRe     = regexp.MustCompile(`(?i)^([a-z0-9](?:[-a-z0-9]*[a-z0-9])?):[0-9]+$`)
+	serviceHostVar = regexp.MustCompile(`^([A-Z0-9_]+)_SERVICE_HOST$`)
+)
+
+// CallEvidence é uma evidência de que um workload chama um Service.
+type CallEvidence struct {
+	Rule      string  `json:"rule"`
+	Container string  `json:"container,omitempty"`
+	Variable  string  `json:"variable,omitempty"`  // env var
+	ConfigMap string  `json:"configMap,omitempty"` // ConfigMap/chave onde o valor foi achado
+	Key       string  `json:"key,omitempty"`
+	Match     string  `json:"match"` // host, nome da variável ou IP encontrado
+	Score     float64 `json:"score"`
+}
+
+// serviceIndex resolve hosts para IDs de nós de Service.
+type serviceIndex struct {
+	byName    map[string]string     // ns/nome -> ID
+	byIP      map[string]string     // ClusterIP -> ID
+	selectors map[string]labels.Set // ID -> spec.selector (só Services com seletor)
+}
+
+func newServiceIndex(res *clusterResources) *serviceIndex {
+	idx := &serviceIndex{byName: map[string]string{}, byIP: map[string]string{}, selectors: map[string]labels.Set{}}
+	for _, svc := range res.Services {
+		id := "svc:" + svc.Namespace + ":" + svc.Name
+		idx.byName[svc.Namespace+"/"+svc.Name] = id
+		if len(svc.Spec.Selector) > 0 {
+			idx.selectors[id] = labels.Set(svc.Spec.Selector)
+		}
+		for _, ip := range append([]string{svc.Spec.ClusterIP}, svc.Spec.ClusterIPs...) {
+			if ip != "" && ip != corev1.ClusterIPNone {
+				idx.byIP[ip] = id
+			}
+		}
+	}
+	return idx
+}
+
+// selects indica se o Service id seleciona o pod, ou seja, se o pod chamaria a
+// si mesmo (ex: PUBLIC_URL=http://web:8080 no próprio web).
+func (idx *serviceIndex) selects(id string, pod *corev1.Pod) bool {
+	sel, ok := idx.selectors[id]
+	if !ok || !strings.HasPrefix(id, "svc:"+pod.Namespace+":") {
+		return false
+	}
+	return sel.AsSelectorPreValidated().Matches(labels.Set(pod.Labels))
+}
+
+// valueCandidate é um valor a ser varrido e de onde ele veio.
+type valueCandidate struct {
+	value    string
+	evidence CallEvidence // campos de origem (container/variável ou ConfigMap/chave)
+	penalty  float64
+}
+
+// matchValue aplica as regras a um valor; ns é o namespace do pod.
+func (idx *serviceIndex) matchValue(v valueCandidate, ns string) map[string]CallEvidence {
+	found := map[string]CallEvidence{}
+	add := func(id, rule, match string) {
+		ev := v.evidence
+		ev.Rule, ev.Match = rule, match
+		ev.Score = inferConfidence[rule] - v.penalty
+		if prev, ok := found[id]; !ok || ev.Score > prev.Score {
+			found[id] = ev
+		}
+	}
+
+	for _, m := range svcDNSRe.FindAllStringSubmatch(v.value, -1) {
+		if id, ok := idx.byName[strings.ToLower(m[2])+"/"+strings.ToLower(m[1])]; ok {
+			add(id, inferRuleDNS, strings.ToLower(m[0]))
+		}
+	}
+	if id, ok := idx.byIP[strings.TrimSpace(v.value)]; ok {
+		add(id, inferRuleClusterIP, strings.TrimSpace(v.value))
+	}
+	var shortNames []string
+	for _, m := range urlHostRe.FindAllStringSubmatch(v.value, -1) {
+		shortNames = append(shortNames, m[1])
+	}
+	if m := hostPortRe.FindStringSubmatch(strings.TrimSpace(v.value)); m != nil {
+		shortNames = append(shortNames, m[1])
+	}
+	for _, name := range shortNames {
+		name = strings.ToLower(name)
+		if id, ok := idx.byName[ns+"/"+name]; ok {
+			add(id, inferRuleShortName, name)
+		}
+	}
+	return found
+}
+
+// podCallCandidates lista os valores a varrer em um pod: env vars literais
+// (com a regra *_SERVICE_HOST resolvida à parte) e valores dos ConfigMaps
+// referenciados por envFrom, env.valueFrom ou volume.
+func podCallCandidates(pod *corev1.Pod, configMaps map[string]*corev1.ConfigMap) (values []valueCandidate, hostVars []CallEvidence) {
+	addContainer := func(c *corev1.Container) {
+		for _, e := range c.Env {
+			if e.ValueFrom != nil || e.Value == "" {
+				continue
+			}
+			src := CallEvidence{Container: c.Name, Variable: e.Name}
+			values = append(values, valueCandidate{value: e.Value, evidence: src})
+			if m := serviceHostVar.FindStringSubmatch(e.Name); m != nil {
+				src.Match = strings.ToLower(strings.ReplaceAll(m[1], "_", "-"))
+				hostVars = append(hostVars, src)
+			}
+		}
+	}
+	for i := range pod.Spec.InitContainers {
+		addContainer(&pod.Spec.InitContainers[i])
+	}
+	for i := range pod.Spec.Containers {
+		addContainer(&pod.Spec.Containers[i])
+	}
+
+	for _, ref := range podConfigRefs(&pod.Spec) {
+		if ref.kind != "ConfigMap" {
+			continue
+		}
+		cm, ok := configMaps[pod.Namespace+"/"+ref.name]
+		if !ok {
+			continue
+		}
+		penalty := 0.0
+		if ref.via == refViaVolume || ref.via == refViaProjected {
+			penalty = configMapPenalty
+		}
+		for _, key := range sortedKeys(cm.Data) {
+			if ref.key != "" && key != ref.key {
+				continue
+			}
+			values = append(values, valueCandidate{
+				value:    cm.Data[key],
+				evidence: CallEvidence{ConfigMap: cm.Name, Key: key},
+				penalty:  penalty,
+			})
+		}
+	}
+	return values, hostVars
+}
+
+// addInferredCalls adiciona as arestas EdgeCalls inferidas dos pods (colapsadas
+// no workload dono, como as arestas de configuração no modo workload).
+func addInferredCalls(g *ClusterGraph, res *clusterResources) {
+	inGraph := make(map[string]bool, len(g.Nodes))
+	for _, n := range g.Nodes {
+		inGraph[n.ID] = true
+	}
+	configMaps := make(map[string]*corev1.ConfigMap, len(res.ConfigMaps))
+	for _, cm := range res.ConfigMaps {
+		configMaps[cm.Namespace+"/"+cm.Name] = cm
+	}
+	services := newServiceIndex(res)
+	owners := newOwnerIndex(res)
+
+	type callAgg struct {
+		source, target string
+		evidence       map[string]CallEvidence // deduplicada por origem+regra+match
+	}
+	calls := map[string]*callAgg{}
+	order := []string{}
+	record := func(source, target string, ev CallEvidence) {
+		id := "edge:calls:" + source + "->" + target
+		agg, ok := calls[id]
+		if !ok {
+			agg = &callAgg{source: source, target: target, evidence: map[string]CallEvidence{}}
+			calls[id] = agg
+			order = append(order, id)
+		}
+		key := ev.Rule + "|" + ev.Container + "|" + ev.Variable + "|" + ev.ConfigMap + "|" + ev.Key + "|" + ev.Match
+		agg.evidence[key] = ev
+	}
+
+	for _, pod := range res.Pods {
+		source := "pod:" + pod.Namespace + ":" + pod.Name
+		if id, ok := owners.workloadID(pod); ok {
+			source = id
+		}
+		if !inGraph[source] {
+			continue
+		}
+
+		values, hostVars := podCallCandidates(pod, configMaps)
+		for _, ev := range hostVars {
+			if id, ok := services.byName[pod.Namespace+"/"+ev.Match]; ok && inGraph[id] && !services.selects(id, pod) {
+				ev.Rule, ev.Score = inferRuleServiceHost, inferConfidence[inferRuleServiceHost]
+				ev.Match = ev.Variable
+				record(source, id, ev)
+			}
+		}
+		for _, v := range values {
+			for id, ev := range services.matchValue(v, pod.Namespace) {
+				if inGraph[id] && !services.selects(id, pod) {
+					record(source, id, ev)
+				}
+			}
+		}
+	}
+
+	for _, id := range order {
+		agg := calls[id]
+		evidence := make([]CallEvidence, 0, len(agg.evidence))
+		confidence := 0.0
+		for _, key := range sortedKeys(agg.evidence) {
+			ev := agg.evidence[key]
+			evidence = append(evidence, ev)
+			if ev.Score > confidence {
+				confidence = ev.Score
+			}
+		}
+		sort.SliceStable(evidence, func(i, j int) bool { return evidence[i].Score > evidence[j].Score })
+		g.Edges = append(g.Edges, GraphEdge{
+			ID:     id,
+			Source: agg.source,
+			Target: agg.target,
+			Type:   EdgeCalls,
+			Data:   map[string]interface{}{"inferred": true, "confidence": confidence, "evidence": evidence},
+		})
+	}
+}
@@ -0,0 +1,112 @@
+package k8s
+
+import (
+	"sort"
+
+	appsv1 "k8s.io/api/apps/v1"
+	autoscalingv2 "k8s.io/api/autoscaling/v2"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	discoveryv1 "k8s.io/api/discovery/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	storagev1 "k8s.io/api/storage/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// clusterResources reúne os objetos brutos usados na construção do grafo, lidos
+// do cache dos informers (ver ClusterCache.resources).
+type clusterResources struct {
+	Namespace  string          // escopo da busca ("" = todos os namespaces)
+	namespaces map[string]bool // lista de namespaces (namespace=a,b); nil = só Namespace
+
+	Deployments    []*appsv1.Deployment
+	StatefulSets   []*appsv1.StatefulSet
+	DaemonSets     []*appsv1.DaemonSet
+	ReplicaSets    []*appsv1.ReplicaSet
+	Pods           []*corev1.Pod
+	Services       []*corev1.Service
+	EndpointSlices []*discoveryv1.EndpointSlice
+	HPAs           []*autoscalingv2.HorizontalPodAutoscaler
+	Nodes          []*corev1.Node
+	Jobs           []*batchv1.Job
+	CronJobs       []*batchv1.CronJob
+	Ingresses      []*networkingv1.Ingress
+	Gateways       []*gatewayObject   // Gateway API (vazio se as CRDs não existirem)
+	HTTPRoutes     []*httpRouteObject // Gateway API (vazio se as CRDs não existirem)
+
+	NetworkPolicies []*networkingv1.NetworkPolicy
+
+	PVCs           []*corev1.PersistentVolumeClaim
+	PVs            []*corev1.PersistentVolume
+	StorageClasses []*storagev1.StorageClass
+
+	ConfigMaps []*corev1.ConfigMap
+	Secrets    []*corev1.Secret // só nomes de chaves, sem valores (ver dropSecretValues)
+
+	Namespaces []*corev1.Namespace
+
+	Generic []*unstructured.Unstructured // recursos configurados para o grafo de owner references (ver ownerrefs.go)
+
+	Warnings []TopologyWarning // tipos que não puderam ser lidos (grafo parcial)
+}
+
+// namespaceScope traduz o filtro recebido pela API para o namespace usado nas
+// chamadas ao cluster ("" significa todos os namespaces). Uma lista ("a,b") é
+// buscada em todos os namespaces e recortada depois (ver filterResources).
+func namespaceScope(namespaceFilter string) string {
+	nss := namespaceList(namespaceFilter)
+	if len(nss) != 1 {
+		return metav1.NamespaceAll
+	}
+	return nss[0]
+}
+
+// scoped indica se a busca foi restrita a um ou mais namespaces.
+func (r *clusterResources) scoped() bool {
+	return r.Namespace != "" || r.namespaces != nil
+}
+
+// inScope indica se o namespace faz parte do escopo da busca.
+func (r *clusterResources) inScope(ns string) bool {
+	if r.namespaces != nil {
+		return r.namespaces[ns]
+	}
+	return r.Namespace == "" || r.Namespace == ns
+}
+
+// sort ordena todos os recursos por namespace/nome. A ordem dos listers é
+// aleatória; ordenar garante um grafo estável.
+func (r *clusterResources) sort() {
+	sortObjects(r.Deployments)
+	sortObjects(r.StatefulSets)
+	sortObjects(r.DaemonSets)
+	sortObjects(r.ReplicaSets)
+	sortObjects(r.Pods)
+	sortObjects(r.Services)
+	sortObjects(r.EndpointSlices)
+	sortObjects(r.HPAs)
+	sortObjects(r.Nodes)
+	sortObjects(r.Jobs)
+	sortObjects(r.CronJobs)
+	sortObjects(r.Ingresses)
+	sortObjects(r.Gateways)
+	sortObjects(r.HTTPRoutes)
+	sortObjects(r.NetworkPolicies)
+	sortObjects(r.PVCs)
+	sortObjects(r.PVs)
+	sortObjects(r.StorageClasses)
+	sortObjects(r.ConfigMaps)
+	sortObjects(r.Secrets)
+	sortObjects(r.Namespaces)
+	sortObjects(r.Generic)
+}
+
+func sortObjects[T metav1.Object](items []T) {
+	sort.Slice(items, func(i, j int) bool {
+		if items[i].GetNamespace() != items[j].GetNamespace() {
+			return items[i].GetNamespace() < items[j].GetNamespace()
+		}
+		return items[i].GetName() < items[j].GetName()
+	})
+}
@@ -0,0 +1,454 @@
+package k8s
+
+import (
+	"fmt"
+	"math"
+	"sort"
+)
+
+/*
+========================
+ LAYOUT (POSIÇÕES DO RFGRAPH)
+========================
+*/
+
+// Layouts disponíveis via parâmetro layout= da rota de topologia.
+const (
+	LayoutLayered = "layered" // Sugiyama em camadas, agrupado por namespace (padrão)
+	LayoutGrid    = "grid"    // grade simples por namespace
+	LayoutNone    = "none"    // sem layout: tudo fica para o navegador
+)
+
+// Dimensões usadas pelo layout. O frontend renderiza nós de ~180x60.
+const (
+	layoutNodeWidth  = 180.0
+	layoutNodeHeight = 60.0
+	layoutLayerGap   = 260.0 // distância horizontal entre camadas
+	layoutRowGap     = 90.0  // distância vertical entre nós da mesma camada
+	layoutGroupPad   = 40.0  // margem interna do bloco de um namespace
+	layoutGroupGap   = 80.0  // espaço entre blocos de namespaces
+)
+
+// Point é uma posição no plano do React Flow.
+type Point struct {
+	X float64 `json:"x"`
+	Y float64 `json:"y"`
+}
+
+// Rect é a área ocupada por um grupo (namespace) no layout.
+type Rect struct {
+	X      float64 `json:"x"`
+	Y      float64 `json:"y"`
+	Width  float64 `json:"width"`
+	Height float64 `json:"height"`
+}
+
+// Layout é o resultado de um algoritmo de posicionamento.
+type Layout struct {
+	Positions  map[string]Point // node ID -> posição absoluta
+	Groups     map[string]Rect  // namespace -> bloco ocupado
+	Containers map[string]Rect  // nós que agrupam outros (GraphNode.Parent) -> área absoluta
+}
+
+// layoutFunc posiciona os nós de um único grupo a partir da origem (0,0) e
+// devolve a largura/altura ocupadas.
+type layoutFunc func(nodes []string, edges [][2]string) (map[string]Point, float64, float64)
+
+var layoutFuncs = map[string]layoutFunc{
+	LayoutLayered: layeredGroupLayout,
+	LayoutGrid:    gridGroupLayout,
+}
+
+// ValidateLayout verifica se o nome de layout recebido pela API é suportado.
+func ValidateLayout(name string) error {
+	if name == "" || name == LayoutNone {
+		return nil
+	}
+	if _, ok := layoutFuncs[name]; !ok {
+		return fmt.Errorf("layout desconhecido: %s", name)
+	}
+	return nil
+}
+
+// ComputeLayout calcula posições determinísticas para o grafo: os nós de topo
+// são agrupados por namespace, cada grupo é posicionado isoladamente e os
+// blocos resultantes são empacotados em prateleiras. Nós com filhos
+// (GraphNode.Parent) viram contêineres, posicionados recursivamente com os
+// filhos dentro. Mesmo grafo => mesmas posições.
+func ComputeLayout(g *ClusterGraph, name string) *Layout {
+	if name == "" {
+		name = LayoutLayered
+	}
+	fn, ok := layoutFuncs[name]
+	if !ok {
+		return nil
+	}
+
+	exists := make(map[string]bool, len(g.Nodes))
+	for _, n := range g.Nodes {
+		exists[n.ID] = true
+	}
+
+	// Chave de "irmandade": filhos do mesmo contêiner ou nós de topo do mesmo namespace
+	e := &layoutEngine{fn: fn, children: map[string][]string{}, siblingEdges: map[string][][2]string{}}
+	keyOf := make(map[string]string, len(g.Nodes))
+	members := make(map[string][]string)
+	for _, n := range g.Nodes {
+		if n.Parent != "" && exists[n.Parent] {
+			e.children[n.Parent] = append(e.children[n.Parent], n.ID)
+			keyOf[n.ID] = "parent:" + n.Parent
+			continue
+		}
+		keyOf[n.ID] = "ns:" + n.Namespace
+		members[n.Namespace] = append(members[n.Namespace], n.ID)
+	}
+	for _, edge := range g.Edges {
+		ks, okS := keyOf[edge.Source]
+		kt, okT := keyOf[edge.Target]
+		if okS && okT && ks == kt {
+			e.siblingEdges[ks] = append(e.siblingEdges[ks], [2]string{edge.Source, edge.Target})
+		}
+	}
+
+	// Namespaces em ordem alfabética; recursos globais (sem namespace) por último
+	groups := make([]string, 0, len(members))
+	for ns := range members {
+		groups = append(groups, ns)
+	}
+	sort.Slice(groups, func(i, j int) bool {
+		if (groups[i] == "") != (groups[j] == "") {
+			return groups[j] == ""
+		}
+		return groups[i] < groups[j]
+	})
+
+	type block struct {
+		ns    string
+		pos   map[string]Point
+		rects map[string]Rect
+		w, h  float64
+	}
+	blocks := make([]block, 0, len(groups))
+	totalArea, maxW := 0.0, 0.0
+	for _, ns := range groups {
+		pos, rects, w, h := e.placeBlock("ns:"+ns, members[ns])
+		w += 2 * layoutGroupPad
+		h += 2 * layoutGroupPad
+		blocks = append(blocks, block{ns: ns, pos: pos, rects: rects, w: w, h: h})
+		totalArea += w * h
+		maxW = math.Max(maxW, w)
+	}
+
+	// Empacotamento em prateleiras com largura alvo proporcional à área total
+	rowWidth := math.Max(maxW, math.Sqrt(totalArea)*1.5)
+	out := &Layout{
+		Positions:  make(map[string]Point, len(g.Nodes)),
+		Groups:     make(map[string]Rect, len(blocks)),
+		Containers: make(map[string]Rect),
+	}
+	x, y, rowH := 0.0, 0.0, 0.0
+	for _, b := range blocks {
+		if x > 0 && x+b.w > rowWidth {
+			x = 0
+			y += rowH + layoutGroupGap
+			rowH = 0
+		}
+		out.Groups[b.ns] = Rect{X: x, Y: y, Width: b.w, Height: b.h}
+		ox, oy := x+layoutGroupPad, y+layoutGroupPad
+		for id, p := range b.pos {
+			out.Positions[id] = Point{X: ox + p.X, Y: oy + p.Y}
+		}
+		for id, r := range b.rects {
+			out.Containers[id] = Rect{X: ox + r.X, Y: oy + r.Y, Width: r.Width, Height: r.Height}
+		}
+		x += b.w + layoutGroupGap
+		rowH = math.Max(rowH, b.h)
+	}
+
+	return out
+}
+
+// layoutEngine guarda o estado do layout hierárquico.
+type layoutEngine struct {
+	fn           layoutFunc
+	children     map[string][]string    // contêiner -> filhos diretos
+	siblingEdges map[string][][2]string // chave de irmandade -> arestas entre irmãos
+}
+
+// placeBlock posiciona um conjunto de irmãos a partir de (0,0). Os nós simples
+// passam pelo algoritmo de layout; os contêineres são empacotados em prateleiras
+// abaixo deles. Retorna posições (inclusive de descendentes) e retângulos dos
+// contêineres, relativos à origem do bloco, e o tamanho ocupado.
+func (e *layoutEngine) placeBlock(key string, ids []string) (map[string]Point, map[string]Rect, float64, float64) {
+	sort.Strings(ids)
+	leaves, containers := []string{}, []string{}
+	leafSet := map[string]bool{}
+	for _, id := range ids {
+		if len(e.children[id]) > 0 {
+			containers = append(containers, id)
+		} else {
+			leaves = append(leaves, id)
+			leafSet[id] = true
+		}
+	}
+	leafEdges := [][2]string{}
+	for _, edge := range e.siblingEdges[key] {
+		if leafSet[edge[0]] && leafSet[edge[1]] {
+			leafEdges = append(leafEdges, edge)
+		}
+	}
+
+	pos, w, h := e.fn(leaves, leafEdges)
+	rects := map[string]Rect{}
+	if len(containers) == 0 {
+		return pos, rects, w, h
+	}
+
+	type inner struct {
+		id    string
+		pos   map[string]Point
+		rects map[string]Rect
+		w, h  float64
+	}
+	boxes := make([]inner, 0, len(containers))
+	totalArea, maxW := 0.0, w
+	for _, id := range containers {
+		p, r, iw, ih := e.placeBlock("parent:"+id, e.children[id])
+		iw += 2 * layoutGroupPad
+		ih += 2 * layoutGroupPad
+		boxes = append(boxes, inner{id: id, pos: p, rects: r, w: iw, h: ih})
+		totalArea += iw * ih
+		maxW = math.Max(maxW, iw)
+	}
+
+	top := 0.0
+	if len(leaves) > 0 {
+		top = h + layoutGroupGap
+	}
+	rowWidth := math.Max(maxW, math.Sqrt(totalArea)*1.5)
+	x, y, rowH := 0.0, top, 0.0
+	for _, b := range boxes {
+		if x > 0 && x+b.w > rowWidth {
+			x = 0
+			y += rowH + layoutGroupGap
+			rowH = 0
+		}
+		pos[b.id] = Point{X: x, Y: y}
+		rects[b.id] = Rect{X: x, Y: y, Width: b.w, Height: b.h}
+		ox, oy := x+layoutGroupPad, y+layoutGroupPad
+		for id, p := range b.pos {
+			pos[id] = Point{X: ox + p.X, Y: oy + p.Y}
+		}
+		for id, r := range b.rects {
+			rects[id] = Rect{X: ox + r.X, Y: oy + r.Y, Width: r.Width, Height: r.Height}
+		}
+		x += b.w + layoutGroupGap
+		rowH = math.Max(rowH, b.h)
+		w = math.Max(w, x-layoutGroupGap)
+	}
+
+	return pos, rects, w, y + rowH
+}
+
+// gridGroupLayout distribui os nós do grupo numa grade quase quadrada.
+func gridGroupLayout(nodes []string, _ [][2]string) (map[string]Point, float64, float64) {
+	pos := make(map[string]Point, len(nodes))
+	if len(nodes) == 0 {
+		return pos, 0, 0
+	}
+	cols := int(math.Ceil(math.Sqrt(float64(len(nodes)))))
+	for i, id := range nodes {
+		pos[id] = Point{X: float64(i%cols) * layoutLayerGap, Y: float64(i/cols) * layoutRowGap}
+	}
+	rows := (len(nodes) + cols - 1) / cols
+	return pos, float64(cols-1)*layoutLayerGap + layoutNodeWidth, float64(rows-1)*layoutRowGap + layoutNodeHeight
+}
+
+// layeredGroupLayout implementa um Sugiyama simplificado:
+//  1. remoção de ciclos (arestas de retorno da DFS são invertidas)
+//  2. atribuição de camadas pelo caminho mais longo
+//  3. redução de cruzamentos com varreduras de baricentro
+//  4. coordenadas: camada -> x, ordem na camada -> y
+//
+// Arestas longas não recebem nós fictícios. Nós sem arestas no grupo vão para
+// uma grade abaixo das camadas, para não virarem uma coluna gigante.
+func layeredGroupLayout(nodes []string, edges [][2]string) (map[string]Point, float64, float64) {
+	pos := make(map[string]Point, len(nodes))
+	if len(nodes) == 0 {
+		return pos, 0, 0
+	}
+
+	index := make(map[string]int, len(nodes))
+	for i, id := range nodes {
+		index[id] = i
+	}
+
+	// Adjacência deduplicada e ordenada (determinismo)
+	succ := make([][]int, len(nodes))
+	seen := make(map[[2]int]bool, len(edges))
+	connected := make([]bool, len(nodes))
+	for _, e := range edges {
+		s, t := index[e[0]], index[e[1]]
+		if s == t || seen[[2]int{s, t}] {
+			continue
+		}
+		seen[[2]int{s, t}] = true
+		succ[s] = append(succ[s], t)
+		connected[s], connected[t] = true, true
+	}
+	for i := range succ {
+		sort.Ints(succ[i])
+	}
+
+	// 1. Remoção de ciclos
+	const (
+		unvisited = iota
+		visiting
+		done
+	)
+	state := make([]int, len(nodes))
+	dag := make([][]int, len(nodes))
+	var visit func(v int)
+	visit = func(v int) {
+		state[v] = visiting
+		for _, w := range succ[v] {
+			switch state[w] {
+			case visiting:
+				dag[w] = append(dag[w], v) // aresta de retorno invertida
+			case unvisited:
+				dag[v] = append(dag[v], w)
+				visit(w)
+			default:
+				dag[v] = append(dag[v], w)
+			}
+		}
+		state[v] = done
+	}
+	for v := range nodes {
+		if connected[v] && state[v] == unvisited {
+			visit(v)
+		}
+	}
+
+	// 2. Camadas pelo caminho mais longo (Kahn em ordem de índice)
+	indeg := make([]int, len(nodes))
+	preds := make([][]int, len(nodes))
+	for v, ws := range dag {
+		for _, w := range ws {
+			indeg[w]++
+			preds[w] = append(preds[w], v)
+		}
+	}
+	layer := make([]int, len(nodes))
+	queue := []int{}
+	for v := range nodes {
+		if connected[v] && indeg[v] == 0 {
+			queue = append(queue, v)
+		}
+	}
+	maxLayer := 0
+	for len(queue) > 0 {
+		v := queue[0]
+		queue = queue[1:]
+		for _, w := range dag[v] {
+			if layer[v]+1 > layer[w] {
+				layer[w] = layer[v] + 1
+			}
+			indeg[w]--
+			if indeg[w] == 0 {
+				queue = append(queue, w)
+			}
+		}
+		if layer[v] > maxLayer {
+			maxLayer = layer[v]
+		}
+	}
+
+	layers := make([][]int, maxLayer+1)
+	isolated := []int{}
+	for v := range nodes {
+		if !connected[v] {
+			isolated = append(isolated, v)
+			continue
+		}
+		layers[layer[v]] = append(layers[layer[v]], v)
+	}
+
+	// 3. Redução de cruzamentos por baricentro
+	order := make([]float64, len(nodes))
+	for _, l := range layers {
+		for i, v := range l {
+			order[v] = float64(i)
+		}
+	}
+	sortByBarycenter := func(l []int, neighbors func(int) []int) {
+		bary := make(map[int]float64, len(l))
+		for _, v := range l {
+			ns := neighbors(v)
+			if len(ns) == 0 {
+				bary[v] = order[v]
+				continue
+			}
+			sum := 0.0
+			for _, u := range ns {
+				sum += order[u]
+			}
+			bary[v] = sum / float64(len(ns))
+		}
+		sort.SliceStable(l, func(i, j int) bool {
+			if bary[l[i]] != bary[l[j]] {
+				return bary[l[i]] < bary[l[j]]
+			}
+			return l[i] < l[j]
+		})
+		for i, v := range l {
+			order[v] = float64(i)
+		}
+	}
+	for sweep := 0; sweep < 4; sweep++ {
+		for i := 1; i < len(layers); i++ {
+			sortByBarycenter(layers[i], func(v int) []int { return preds[v] })
+		}
+		for i := len(layers) - 2; i >= 0; i-- {
+			sortByBarycenter(layers[i], func(v int) []int { return dag[v] })
+		}
+	}
+
+	// 4. Coordenadas: camadas centralizadas verticalmente
+	maxSize := 0
+	for _, l := range layers {
+		if len(l) > maxSize {
+			maxSize = len(l)
+		}
+	}
+	width, height := 0.0, 0.0
+	if maxSize > 0 {
+		width = float64(len(layers)-1)*layoutLayerGap + layoutNodeWidth
+		height = float64(maxSize-1)*layoutRowGap + layoutNodeHeight
+		for li, l := range layers {
+			offset := float64(maxSize-len(l)) * layoutRowGap / 2
+			for i, v := range l {
+				pos[nodes[v]] = Point{X: float64(li) * layoutLayerGap, Y: offset + float64(i)*layoutRowGap}
+			}
+		}
+	}
+
+	if len(isolated) > 0 {
+		ids := make([]string, len(isolated))
+		for i, v := range isolated {
+			ids[i] = nodes[v]
+		}
+		gridPos, gw, gh := gridGroupLayout(ids, nil)
+		top := 0.0
+		if height > 0 {
+			top = height + layoutRowGap
+		}
+		for id, p := range gridPos {
+			pos[id] = Point{X: p.X, Y: top + p.Y}
+		}
+		width = math.Max(width, gw)
+		height = top + gh
+	}
+
+	return pos, width, height
+}
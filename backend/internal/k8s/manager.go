@@ -0,0 +1,704 @@
+package k8s
+
+import (
+	"context"
+	"log"
+	"strings"
+	"sync"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic/dynamicinformer"
+	"k8s.io/client-go/informers"
+	"k8s.io/client-go/tools/cache"
+
+	"github.com/example/vkube-topology/backend/internal/models"
+)
+
+/*
+========================
+ CLUSTER MANAGER (CACHE DE INFORMERS)
+========================
+*/
+
+// Estados possíveis do cache de um cluster.
+const (
+	CacheStateWarming = "warming"
+	CacheStateReady   = "ready"
+	CacheStateStopped = "stopped"
+)
+
+// CacheStatus descreve o estado de sincronização do cache de um cluster.
+// A UI usa isso para exibir "aquecendo" ao invés de um grafo vazio.
+type CacheStatus struct {
+	ClusterID  uint              `json:"clusterId"`
+	State      string            `json:"state"`
+	StartedAt  *time.Time        `json:"startedAt,omitempty"`
+	SyncedAt   *time.Time        `json:"syncedAt,omitempty"`
+	LastAccess *time.Time        `json:"lastAccess,omitempty"`
+	Informers  map[string]bool   `json:"informers,omitempty"` // kind -> sincronizado
+	Errors     map[string]string `json:"errors,omitempty"`    // kind -> último erro de list/watch
+}
+
+// ClusterManager mantém um ClusterCache por cluster registrado. Os caches são
+// iniciados sob demanda e parados depois de ficarem ociosos por idleTTL.
+type ClusterManager struct {
+	mu      sync.Mutex
+	caches  map[uint]*ClusterCache
+	idleTTL time.Duration
+
+	stopCh   chan struct{}
+	stopOnce sync.Once
+}
+
+// NewClusterManager cria o manager e inicia a rotina que encerra caches ociosos.
+func NewClusterManager(idleTTL time.Duration) *ClusterManager {
+	m := &ClusterManager{
+		caches:  make(map[uint]*ClusterCache),
+		idleTTL: idleTTL,
+		stopCh:  make(chan struct{}),
+	}
+	go m.janitor()
+	return m
+}
+
+// Get retorna o cache do cluster, criando e iniciando os informers se necessário.
+// Se o cluster foi atualizado (ex: novo kubeconfig) o cache antigo é descartado.
+func (m *ClusterManager) Get(cluster *models.Cluster, kubeconfig []byte) (*ClusterCache, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if c, ok := m.caches[cluster.ID]; ok {
+		if c.version.Equal(cluster.UpdatedAt) {
+			c.touch()
+			return c, nil
+		}
+		c.stop()
+		delete(m.caches, cluster.ID)
+	}
+
+	// A lista é validada na API; entradas inválidas gravadas antes disso são ignoradas
+	ownerGVRs, err := ParseGVRList(cluster.OwnerResources)
+	if err != nil {
+		log.Printf("[CACHE] Cluster %d: ownerResources ignorado: %v", cluster.ID, err)
+	}
+
+	c, err := newClusterCache(cluster.ID, cluster.UpdatedAt, kubeconfig, ownerGVRs)
+	if err != nil {
+		return nil, err
+	}
+	c.start()
+	m.caches[cluster.ID] = c
+
+	log.Printf("[CACHE] Cluster %d: informers iniciados", cluster.ID)
+	return c, nil
+}
+
+// Status retorna o estado do cache de um cluster sem iniciá-lo.
+func (m *ClusterManager) Status(clusterID uint) CacheStatus {
+	m.mu.Lock()
+	c, ok := m.caches[clusterID]
+	m.mu.Unlock()
+
+	if !ok {
+		return CacheStatus{ClusterID: clusterID, State: CacheStateStopped}
+	}
+	return c.Status()
+}
+
+// Evict para e remove o cache de um cluster (ex: cluster removido ou editado).
+func (m *ClusterManager) Evict(clusterID uint) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if c, ok := m.caches[clusterID]; ok {
+		c.stop()
+		delete(m.caches, clusterID)
+	}
+}
+
+// Stop encerra todos os caches e a rotina de limpeza.
+func (m *ClusterManager) Stop() {
+	m.stopOnce.Do(func() {
+		close(m.stopCh)
+
+		m.mu.Lock()
+		defer m.mu.Unlock()
+		for id, c := range m.caches {
+			c.stop()
+			delete(m.caches, id)
+		}
+	})
+}
+
+func (m *ClusterManager) janitor() {
+	interval := m.idleTTL / 2
+	if interval > time.Minute || interval <= 0 {
+		interval = time.Minute
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-m.stopCh:
+			return
+		case <-ticker.C:
+			m.evictIdle()
+		}
+	}
+}
+
+func (m *ClusterManager) evictIdle() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for id, c := range m.caches {
+		if c.idleFor() > m.idleTTL {
+			c.stop()
+			delete(m.caches, id)
+			log.Printf("[CACHE] Cluster %d: informers parados por inatividade", id)
+		}
+	}
+}
+
+/*
+========================
+ CLUSTER CACHE
+========================
+*/
+
+// ClusterCache encapsula a SharedInformerFactory de um cluster e o estado de sincronização.
+type ClusterCache struct {
+	ClusterID uint
+
+	version    time.Time // UpdatedAt do cluster no momento da criação
+	clients    *Clients
+	factory    informers.SharedInformerFactory
+	dynFactory dynamicinformer.DynamicSharedInformerFactory
+	informers  map[string]cache.SharedIndexInformer
+
+	// GVRs da Gateway API, preenchidos após a descoberta (vazios se ausente)
+	gatewayGVR   schema.GroupVersionResource
+	httpRouteGVR schema.GroupVersionResource
+
+	// Grafo de owner references: GVRs configurados no cluster e os resolvidos pelo discovery
+	ownerGVRs      []schema.GroupVersionResource
+	ownerResources []ownerResource
+
+	stopCh   chan struct{}
+	syncedCh chan struct{}
+
+	revisions *revisionStore
+
+	mu         sync.RWMutex
+	startedAt  time.Time
+	syncedAt   time.Time
+	lastAccess time.Time
+	errors     map[string]error
+	stopped    bool
+
+	subscribers map[chan struct{}]struct{} // streams abertos (ver Subscribe)
+}
+
+func newClusterCache(clusterID uint, version time.Time, kubeconfig []byte, ownerGVRs []schema.GroupVersionResource) (*ClusterCache, error) {
+	clients, err := NewClients(kubeconfig)
+	if err != nil {
+		return nil, err
+	}
+
+	factory := informers.NewSharedInformerFactoryWithOptions(clients.Kube, 0, informers.WithTransform(sanitizeObject))
+
+	c := &ClusterCache{
+		ClusterID:  clusterID,
+		version:    version,
+		clients:    clients,
+		factory:    factory,
+		dynFactory: dynamicinformer.NewDynamicSharedInformerFactory(clients.Dynamic, 0),
+		informers:  make(map[string]cache.SharedIndexInformer),
+		stopCh:     make(chan struct{}),
+		syncedCh:   make(chan struct{}),
+		errors:     make(map[string]error),
+		revisions:  newRevisionStore(),
+		ownerGVRs:  ownerGVRs,
+
+		subscribers: make(map[chan struct{}]struct{}),
+	}
+
+	// Os informers precisam ser registrados antes do factory.Start
+	c.register("Deployment", factory.Apps().V1().Deployments().Informer())
+	c.register("StatefulSet", factory.Apps().V1().StatefulSets().Informer())
+	c.register("DaemonSet", factory.Apps().V1().DaemonSets().Informer())
+	c.register("ReplicaSet", factory.Apps().V1().ReplicaSets().Informer())
+	c.register("Pod", factory.Core().V1().Pods().Informer())
+	c.register("Service", factory.Core().V1().Services().Informer())
+	c.register("EndpointSlice", factory.Discovery().V1().EndpointSlices().Informer())
+	c.register("HPA", factory.Autoscaling().V2().HorizontalPodAutoscalers().Informer())
+	c.register("Node", factory.Core().V1().Nodes().Informer())
+	c.register("Job", factory.Batch().V1().Jobs().Informer())
+	c.register("CronJob", factory.Batch().V1().CronJobs().Informer())
+	c.register("Ingress", factory.Networking().V1().Ingresses().Informer())
+	c.register("NetworkPolicy", factory.Networking().V1().NetworkPolicies().Informer())
+	c.register("PersistentVolumeClaim", factory.Core().V1().PersistentVolumeClaims().Informer())
+	c.register("PersistentVolume", factory.Core().V1().PersistentVolumes().Informer())
+	c.register("StorageClass", factory.Storage().V1().StorageClasses().Informer())
+	c.register("ConfigMap", factory.Core().V1().ConfigMaps().Informer())
+	c.register("Secret", factory.Core().V1().Secrets().Informer())
+	c.register("Namespace", factory.Core().V1().Namespaces().Informer())
+
+	return c, nil
+}
+
+// register guarda o informer e captura os erros de list/watch, que do contrário
+// só iriam para o log do client-go.
+func (c *ClusterCache) register(kind string, informer cache.SharedIndexInformer) {
+	_ = informer.SetWatchErrorHandler(func(r *cache.Reflector, err error) {
+		c.mu.Lock()
+		c.errors[kind] = err
+		c.mu.Unlock()
+		cache.DefaultWatchErrorHandler(r, err)
+	})
+	_, _ = informer.AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc:    func(interface{}) { c.notify() },
+		UpdateFunc: func(interface{}, interface{}) { c.notify() },
+		DeleteFunc: func(interface{}) { c.notify() },
+	})
+	c.mu.Lock()
+	c.informers[kind] = informer
+	c.mu.Unlock()
+}
+
+// Subscribe registra interesse em mudanças do cache. O canal recebe um sinal
+// (coalescido: vários eventos de watch podem virar um só) sempre que algum
+// informer processa add/update/delete. A função retornada cancela a inscrição.
+// Enquanto houver inscritos o cache não é considerado ocioso.
+func (c *ClusterCache) Subscribe() (<-chan struct{}, func()) {
+	ch := make(chan struct{}, 1)
+	c.mu.Lock()
+	c.subscribers[ch] = struct{}{}
+	c.mu.Unlock()
+
+	return ch, func() {
+		c.mu.Lock()
+		delete(c.subscribers, ch)
+		c.lastAccess = time.Now()
+		c.mu.Unlock()
+	}
+}
+
+// Done é fechado quando o cache é parado (ocioso, cluster alterado ou removido).
+func (c *ClusterCache) Done() <-chan struct{} {
+	return c.stopCh
+}
+
+func (c *ClusterCache) notify() {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	for ch := range c.subscribers {
+		select {
+		case ch <- struct{}{}:
+		default: // já há um sinal pendente
+		}
+	}
+}
+
+func (c *ClusterCache) start() {
+	now := time.Now()
+	c.mu.Lock()
+	c.startedAt = now
+	c.lastAccess = now
+	c.mu.Unlock()
+
+	c.factory.Start(c.stopCh)
+	go func() {
+		c.startDynamic()
+		c.waitForSync()
+	}()
+}
+
+// startDynamic registra os informers de CRDs opcionais (Gateway API) e dos
+// recursos do grafo de owner references depois de consultar o discovery, fora
+// do lock do manager.
+func (c *ClusterCache) startDynamic() {
+	disc := c.clients.Kube.Discovery()
+	if gwGVR, routeGVR, ok := gatewayAPIResources(disc); ok {
+		c.register("Gateway", c.dynFactory.ForResource(gwGVR).Informer())
+		c.register("HTTPRoute", c.dynFactory.ForResource(routeGVR).Informer())
+
+		c.mu.Lock()
+		c.gatewayGVR, c.httpRouteGVR = gwGVR, routeGVR
+		c.mu.Unlock()
+	}
+
+	if len(c.ownerGVRs) > 0 {
+		resolved, errs := resolveOwnerResources(disc, c.ownerGVRs)
+		for _, err := range errs {
+			log.Printf("[CACHE] Cluster %d: ownerResources: %v", c.ClusterID, err)
+		}
+		for _, r := range resolved {
+			informer := c.dynFactory.ForResource(r.GVR).Informer()
+			_ = informer.SetTransform(sanitizeObject)
+			c.register(r.Kind, informer)
+		}
+
+		c.mu.Lock()
+		c.ownerResources = resolved
+		c.mu.Unlock()
+	}
+
+	c.dynFactory.Start(c.stopCh)
+}
+
+// waitForSync considera o cache pronto quando todos os informers sincronizaram
+// ou falharam (ex: RBAC negado), para não deixar o cluster "aquecendo" para sempre.
+func (c *ClusterCache) waitForSync() {
+	ticker := time.NewTicker(500 * time.Millisecond)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-c.stopCh:
+			return
+		case <-ticker.C:
+			if c.allSyncedOrFailed() {
+				c.mu.Lock()
+				c.syncedAt = time.Now()
+				c.mu.Unlock()
+				close(c.syncedCh)
+				log.Printf("[CACHE] Cluster %d: cache sincronizado em %s", c.ClusterID, time.Since(c.startedAt).Round(time.Millisecond))
+				return
+			}
+		}
+	}
+}
+
+func (c *ClusterCache) allSyncedOrFailed() bool {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	for kind, informer := range c.informers {
+		if !informer.HasSynced() && c.errors[kind] == nil {
+			return false
+		}
+	}
+	return true
+}
+
+// WaitForSync bloqueia até o cache estar pronto ou o contexto expirar.
+func (c *ClusterCache) WaitForSync(ctx context.Context) bool {
+	select {
+	case <-c.syncedCh:
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
+
+// Synced indica se o cache já terminou o aquecimento inicial.
+func (c *ClusterCache) Synced() bool {
+	select {
+	case <-c.syncedCh:
+		return true
+	default:
+		return false
+	}
+}
+
+// Status retorna um retrato do estado atual do cache.
+func (c *ClusterCache) Status() CacheStatus {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	startedAt, lastAccess := c.startedAt, c.lastAccess
+	st := CacheStatus{
+		ClusterID:  c.ClusterID,
+		State:      CacheStateWarming,
+		StartedAt:  &startedAt,
+		LastAccess: &lastAccess,
+		Informers:  make(map[string]bool, len(c.informers)),
+	}
+	if c.stopped {
+		st.State = CacheStateStopped
+	} else if !c.syncedAt.IsZero() {
+		syncedAt := c.syncedAt
+		st.State = CacheStateReady
+		st.SyncedAt = &syncedAt
+	}
+
+	for kind, informer := range c.informers {
+		synced := informer.HasSynced()
+		st.Informers[kind] = synced
+		// Erros de informers já sincronizados costumam ser quedas transitórias de watch
+		if err := c.errors[kind]; err != nil && !synced {
+			if st.Errors == nil {
+				st.Errors = make(map[string]string)
+			}
+			st.Errors[kind] = err.Error()
+		}
+	}
+	return st
+}
+
+// BuildClusterGraph monta o grafo de domínio (sem layout) a partir dos listers do
+// cache, sem chamadas à API. Informers que falharam (RBAC, API ausente...)
+// resultam em grafo parcial e *PartialResultError.
+func (c *ClusterCache) BuildClusterGraph(opts TopologyOptions) (*ClusterGraph, error) {
+	c.touch()
+
+	res, err := c.resources(namespaceScope(opts.Namespace))
+	if err != nil {
+		return nil, err
+	}
+	if err := filterResources(res, opts); err != nil {
+		return nil, err
+	}
+	g := buildClusterGraph(res, opts)
+
+	log.Printf("[TOPOLOGY] Cluster %d (cache). Nodes: %d, Edges: %d, Warnings: %d", c.ClusterID, len(g.Nodes), len(g.Edges), len(g.Warnings))
+
+	return g, partialResult(g.Warnings)
+}
+
+// BuildTopologyGraph monta o grafo no formato do React Flow e atribui a revisão.
+// Assim como BuildClusterGraph, pode retornar o grafo junto com *PartialResultError.
+func (c *ClusterCache) BuildTopologyGraph(opts TopologyOptions) (*RFGraph, error) {
+	g, err := c.BuildClusterGraph(opts)
+	if g == nil {
+		return nil, err
+	}
+
+	rf := toRFGraph(g, opts.Layout)
+	c.revisions.record(optionsKey(opts), rf)
+	return rf, err
+}
+
+// BuildTopologyDelta monta o grafo atual e devolve apenas o que mudou desde a
+// revisão since. Se since não estiver mais no histórico, devolve o grafo completo
+// (Full=true). Assim como BuildTopologyGraph, pode retornar *PartialResultError.
+func (c *ClusterCache) BuildTopologyDelta(opts TopologyOptions, since uint64) (*RFGraphDelta, error) {
+	graph, err := c.BuildTopologyGraph(opts)
+	if graph == nil {
+		return nil, err
+	}
+
+	prev := c.revisions.get(optionsKey(opts), since)
+	if prev == nil {
+		return &RFGraphDelta{Since: since, Revision: graph.Revision, Full: true, Graph: graph, Warnings: graph.Warnings}, err
+	}
+	return diffGraph(prev, graph), err
+}
+
+// Reachability avalia as NetworkPolicies para o tráfego do pod from para o pod
+// to (ambos "namespace/nome"). Porta vazia significa "em alguma porta".
+// Carrega todos os namespaces, já que peers podem ser de outro namespace.
+func (c *ClusterCache) Reachability(from, to, port string, protocol corev1.Protocol) (*ReachabilityResult, error) {
+	c.touch()
+
+	res, err := c.resources("")
+	if err != nil {
+		return nil, err
+	}
+	fromNS, fromName, _ := strings.Cut(from, "/")
+	toNS, toName, _ := strings.Cut(to, "/")
+	src, err := findPod(res, fromNS, fromName)
+	if err != nil {
+		return nil, err
+	}
+	dst, err := findPod(res, toNS, toName)
+	if err != nil {
+		return nil, err
+	}
+	return evaluateReachability(res, src, dst, port, protocol), nil
+}
+
+// resources lê os objetos dos listers. Os objetos são compartilhados com o
+// cache e não devem ser modificados.
+func (c *ClusterCache) resources(ns string) (*clusterResources, error) {
+	sel := labels.Everything()
+	res := &clusterResources{Namespace: ns}
+	var err error
+
+	if res.Deployments, err = c.factory.Apps().V1().Deployments().Lister().Deployments(ns).List(sel); err != nil {
+		return nil, err
+	}
+	if res.StatefulSets, err = c.factory.Apps().V1().StatefulSets().Lister().StatefulSets(ns).List(sel); err != nil {
+		return nil, err
+	}
+	if res.DaemonSets, err = c.factory.Apps().V1().DaemonSets().Lister().DaemonSets(ns).List(sel); err != nil {
+		return nil, err
+	}
+	if res.ReplicaSets, err = c.factory.Apps().V1().ReplicaSets().Lister().ReplicaSets(ns).List(sel); err != nil {
+		return nil, err
+	}
+	if res.Pods, err = c.factory.Core().V1().Pods().Lister().Pods(ns).List(sel); err != nil {
+		return nil, err
+	}
+	if res.Services, err = c.factory.Core().V1().Services().Lister().Services(ns).List(sel); err != nil {
+		return nil, err
+	}
+	if res.EndpointSlices, err = c.factory.Discovery().V1().EndpointSlices().Lister().EndpointSlices(ns).List(sel); err != nil {
+		return nil, err
+	}
+	if res.HPAs, err = c.factory.Autoscaling().V2().HorizontalPodAutoscalers().Lister().HorizontalPodAutoscalers(ns).List(sel); err != nil {
+		return nil, err
+	}
+	if res.Nodes, err = c.factory.Core().V1().Nodes().Lister().List(sel); err != nil {
+		return nil, err
+	}
+	if res.Jobs, err = c.factory.Batch().V1().Jobs().Lister().Jobs(ns).List(sel); err != nil {
+		return nil, err
+	}
+	if res.CronJobs, err = c.factory.Batch().V1().CronJobs().Lister().CronJobs(ns).List(sel); err != nil {
+		return nil, err
+	}
+	if res.Ingresses, err = c.factory.Networking().V1().Ingresses().Lister().Ingresses(ns).List(sel); err != nil {
+		return nil, err
+	}
+	if res.NetworkPolicies, err = c.factory.Networking().V1().NetworkPolicies().Lister().NetworkPolicies(ns).List(sel); err != nil {
+		return nil, err
+	}
+
+	if res.PVCs, err = c.factory.Core().V1().PersistentVolumeClaims().Lister().PersistentVolumeClaims(ns).List(sel); err != nil {
+		return nil, err
+	}
+	if res.PVs, err = c.factory.Core().V1().PersistentVolumes().Lister().List(sel); err != nil {
+		return nil, err
+	}
+	if res.StorageClasses, err = c.factory.Storage().V1().StorageClasses().Lister().List(sel); err != nil {
+		return nil, err
+	}
+
+	if res.ConfigMaps, err = c.factory.Core().V1().ConfigMaps().Lister().ConfigMaps(ns).List(sel); err != nil {
+		return nil, err
+	}
+	if res.Secrets, err = c.factory.Core().V1().Secrets().Lister().Secrets(ns).List(sel); err != nil {
+		return nil, err
+	}
+
+	if ns == "" {
+		if res.Namespaces, err = c.factory.Core().V1().Namespaces().Lister().List(sel); err != nil {
+			return nil, err
+		}
+	} else if obj, err := c.factory.Core().V1().Namespaces().Lister().Get(ns); err == nil {
+		res.Namespaces = []*corev1.Namespace{obj}
+	}
+
+	c.mu.RLock()
+	gwGVR, routeGVR := c.gatewayGVR, c.httpRouteGVR
+	c.mu.RUnlock()
+	if !gwGVR.Empty() {
+		objs, err := c.dynFactory.ForResource(gwGVR).Lister().ByNamespace(ns).List(sel)
+		if err != nil {
+			return nil, err
+		}
+		res.Gateways = convertUnstructured[gatewayObject](objs)
+	}
+	if !routeGVR.Empty() {
+		objs, err := c.dynFactory.ForResource(routeGVR).Lister().ByNamespace(ns).List(sel)
+		if err != nil {
+			return nil, err
+		}
+		res.HTTPRoutes = convertUnstructured[httpRouteObject](objs)
+	}
+
+	c.mu.RLock()
+	ownerResources := c.ownerResources
+	c.mu.RUnlock()
+	for _, r := range ownerResources {
+		lister := c.dynFactory.ForResource(r.GVR).Lister()
+		var objs []runtime.Object
+		if r.Namespaced && ns != "" {
+			objs, err = lister.ByNamespace(ns).List(sel)
+		} else {
+			objs, err = lister.List(sel)
+		}
+		if err != nil {
+			return nil, err
+		}
+		for _, obj := range objs {
+			if u, ok := obj.(*unstructured.Unstructured); ok {
+				res.Generic = append(res.Generic, u)
+			}
+		}
+	}
+
+	res.Warnings = c.warnings(ns)
+	res.sort()
+	return res, nil
+}
+
+// warnings converte os erros dos informers que nunca sincronizaram em avisos.
+// Erros de informers já sincronizados são quedas de watch e o lister continua válido.
+func (c *ClusterCache) warnings(ns string) []TopologyWarning {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	warnings := []TopologyWarning{}
+	for kind, informer := range c.informers {
+		if err := c.errors[kind]; err != nil && !informer.HasSynced() {
+			warnings = append(warnings, newWarning(kind, ns, err))
+		}
+	}
+	sortWarnings(warnings)
+	return warnings
+}
+
+func (c *ClusterCache) touch() {
+	c.mu.Lock()
+	c.lastAccess = time.Now()
+	c.mu.Unlock()
+}
+
+func (c *ClusterCache) idleFor() time.Duration {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	if len(c.subscribers) > 0 {
+		return 0
+	}
+	return time.Since(c.lastAccess)
+}
+
+// stop sinaliza o fim dos informers. O Shutdown aguarda as goroutines do
+// factory, então roda em background para não segurar o lock do manager.
+func (c *ClusterCache) stop() {
+	c.mu.Lock()
+	if c.stopped {
+		c.mu.Unlock()
+		return
+	}
+	c.stopped = true
+	c.mu.Unlock()
+
+	close(c.stopCh)
+	go func() {
+		c.factory.Shutdown()
+		c.dynFactory.Shutdown()
+	}()
+}
+
+// sanitizeObject roda antes dos objetos irem para o cache: remove managedFields
+// (reduz bastante a memória por cluster) e os valores dos Secrets, que nunca
+// são expostos no grafo.
+func sanitizeObject(obj interface{}) (interface{}, error) {
+	if accessor, err := meta.Accessor(obj); err == nil {
+		accessor.SetManagedFields(nil)
+	}
+	if secret, ok := obj.(*corev1.Secret); ok {
+		dropSecretValues(secret)
+	}
+	return obj, nil
+}
+
+// dropSecretValues mantém apenas os nomes das chaves de um Secret.
+func dropSecretValues(secret *corev1.Secret) {
+	for k := range secret.Data {
+		secret.Data[k] = nil
+	}
+	secret.StringData = nil
+	delete(secret.Annotations, corev1.LastAppliedConfigAnnotation)
+}
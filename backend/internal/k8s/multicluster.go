@@ -0,0 +1,113 @@
+package k8s
+
+import "fmt"
+
+/*
+========================
+ VISÃO MULTI-CLUSTER
+========================
+*/
+
+// ClusterPart é o grafo de um cluster a ser combinado em MergeClusterGraphs.
+// Com Err != nil e Graph nil, o cluster entra só como grupo marcado com falha.
+type ClusterPart struct {
+	ID    string
+	Name  string
+	Graph *ClusterGraph
+	Err   error
+}
+
+// ClusterNodeID é o ID do nó de grupo de um cluster na visão combinada.
+func ClusterNodeID(clusterID string) string {
+	return "cluster:" + clusterID
+}
+
+// clusterScopedID prefixa o ID de um nó com o ID do cluster ("1/pod:ns:nome").
+func clusterScopedID(clusterID, id string) string {
+	return clusterID + "/" + id
+}
+
+// MergeClusterGraphs combina os grafos dos clusters num só: os IDs de nós e
+// arestas ganham o prefixo do cluster, cada cluster vira um nó de grupo (kind
+// Cluster) contendo os nós de topo do seu grafo, e Services de mesmo namespace
+// e nome em clusters diferentes são ligados por arestas EdgeMirrors. Os avisos
+// de cada cluster seguem com TopologyWarning.Cluster preenchido; um cluster com
+// falha vira um aviso e um grupo vazio com data.health failed.
+func MergeClusterGraphs(parts []ClusterPart) *ClusterGraph {
+	out := &ClusterGraph{Nodes: []GraphNode{}, Edges: []GraphEdge{}, Warnings: []TopologyWarning{}}
+
+	// Services por namespace/nome -> IDs já prefixados, na ordem dos clusters
+	services := map[string][]string{}
+
+	for _, part := range parts {
+		group := GraphNode{
+			ID:    ClusterNodeID(part.ID),
+			Kind:  "Cluster",
+			Name:  part.Name,
+			Data:  map[string]interface{}{"clusterId": part.ID},
+			Group: true,
+		}
+		if part.Err != nil {
+			group.Data["health"] = HealthFailed
+			group.Data["error"] = part.Err.Error()
+			out.Warnings = append(out.Warnings, TopologyWarning{
+				Kind:    "Cluster",
+				Scope:   ScopeCluster,
+				Class:   classifyError(part.Err),
+				Message: fmt.Sprintf("cluster %s: %v", part.Name, part.Err),
+				Cluster: part.ID,
+			})
+		}
+		out.Nodes = append(out.Nodes, group)
+		if part.Graph == nil {
+			continue
+		}
+
+		for _, n := range part.Graph.Nodes {
+			n.ID = clusterScopedID(part.ID, n.ID)
+			if n.Parent != "" {
+				n.Parent = clusterScopedID(part.ID, n.Parent)
+			} else {
+				n.Parent = group.ID
+			}
+			data := make(map[string]interface{}, len(n.Data)+1)
+			for k, v := range n.Data {
+				data[k] = v
+			}
+			data["clusterId"] = part.ID
+			n.Data = data
+			out.Nodes = append(out.Nodes, n)
+			if n.Kind == "Service" {
+				key := n.Namespace + "/" + n.Name
+				services[key] = append(services[key], n.ID)
+			}
+		}
+		for _, e := range part.Graph.Edges {
+			e.ID = clusterScopedID(part.ID, e.ID)
+			e.Source = clusterScopedID(part.ID, e.Source)
+			e.Target = clusterScopedID(part.ID, e.Target)
+			out.Edges = append(out.Edges, e)
+		}
+		for _, w := range part.Graph.Warnings {
+			w.Cluster = part.ID
+			out.Warnings = append(out.Warnings, w)
+		}
+	}
+
+	// Espelhos: cada par de clusters com o mesmo Service, na ordem recebida
+	for _, key := range sortedKeys(services) {
+		ids := services[key]
+		for i := 0; i < len(ids); i++ {
+			for j := i + 1; j < len(ids); j++ {
+				out.Edges = append(out.Edges, GraphEdge{
+					ID:     "edge:mirror:" + ids[i] + "->" + ids[j],
+					Source: ids[i],
+					Target: ids[j],
+					Type:   EdgeMirrors,
+					Label:  key,
+				})
+			}
+		}
+	}
+	return out
+}
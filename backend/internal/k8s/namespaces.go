@@ -0,0 +1,105 @@
+package k8s
+
+import (
+	corev1 "k8s.io/api/core/v1"
+)
+
+/*
+========================
+ NAMESPACES (NÓS DE GRUPO)
+========================
+*/
+
+// addNamespaceGroups cria um nó de grupo por namespace e aponta o Parent de
+// todo nó namespaced (que ainda não tenha pai) para ele. Cada namespace leva
+// contagens resumidas para continuar útil quando colapsado na UI.
+// Deve rodar depois de todas as etapas que adicionam nós.
+func addNamespaceGroups(g *ClusterGraph, res *clusterResources) {
+	// Namespaces presentes no grafo, mesmo sem permissão para listar Namespaces
+	seen := map[string]bool{}
+	for _, n := range g.Nodes {
+		if n.Namespace != "" {
+			seen[n.Namespace] = true
+		}
+	}
+	for _, ns := range res.Namespaces {
+		seen[ns.Name] = true
+	}
+
+	type summary struct {
+		podsByPhase map[string]int
+		workloads   int
+		services    int
+	}
+	summaries := map[string]*summary{}
+	get := func(ns string) *summary {
+		s, ok := summaries[ns]
+		if !ok {
+			s = &summary{podsByPhase: map[string]int{}}
+			summaries[ns] = s
+		}
+		return s
+	}
+	for _, p := range res.Pods {
+		phase := string(p.Status.Phase)
+		if phase == "" {
+			phase = string(corev1.PodUnknown)
+		}
+		get(p.Namespace).podsByPhase[phase]++
+	}
+	for _, d := range res.Deployments {
+		get(d.Namespace).workloads++
+	}
+	for _, s := range res.StatefulSets {
+		get(s.Namespace).workloads++
+	}
+	for _, d := range res.DaemonSets {
+		get(d.Namespace).workloads++
+	}
+	for _, cj := range res.CronJobs {
+		get(cj.Namespace).workloads++
+	}
+	for _, job := range res.Jobs {
+		// Jobs de CronJob já contam pelo CronJob
+		if ref := controllerRef(job.OwnerReferences); ref == nil || ref.Kind != "CronJob" {
+			get(job.Namespace).workloads++
+		}
+	}
+	for _, s := range res.Services {
+		get(s.Namespace).services++
+	}
+
+	nsObjects := make(map[string]*corev1.Namespace, len(res.Namespaces))
+	for _, ns := range res.Namespaces {
+		nsObjects[ns.Name] = ns
+	}
+
+	for _, name := range sortedKeys(seen) {
+		s := get(name)
+		pods := 0
+		for _, c := range s.podsByPhase {
+			pods += c
+		}
+		node := GraphNode{
+			ID: "ns:" + name, Kind: "Namespace", Name: name, Group: true,
+			Data: map[string]interface{}{
+				"pods":        pods,
+				"podsByPhase": s.podsByPhase,
+				"workloads":   s.workloads,
+				"services":    s.services,
+			},
+		}
+		if obj, ok := nsObjects[name]; ok {
+			node.Labels = obj.Labels
+			node.Data["phase"] = string(obj.Status.Phase)
+		}
+		g.Nodes = append(g.Nodes, node)
+	}
+
+	for i := range g.Nodes {
+		n := &g.Nodes[i]
+		if n.Namespace != "" && n.Parent == "" {
+			n.Parent = "ns:" + n.Namespace
+		}
+	}
+}
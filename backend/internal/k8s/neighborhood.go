@@ -0,0 +1,202 @@
+package k8s
+
+import (
+	"fmt"
+	"sort"
+)
+
+/*
+========================
+ VIZINHANÇA E RAIO DE IMPACTO
+========================
+*/
+
+// Direções de travessia em Neighborhood, no sentido das arestas.
+const (
+	DirectionDown = "down" // source -> target (ex: Deployment -> ReplicaSet -> Pod)
+	DirectionUp   = "up"   // target -> source (ex: Pod -> Service, Ingress)
+	DirectionBoth = "both"
+)
+
+// ViaContains marca, em ImpactedNode.Via, os nós afetados por estarem
+// dentro de um grupo afetado (ex: Nodes de uma zona).
+const ViaContains = "contains"
+
+// ImpactedNode é um nó afetado pela falha da raiz em Impact.
+type ImpactedNode struct {
+	ID        string `json:"id"`
+	Kind      string `json:"kind"`
+	Name      string `json:"name"`
+	Namespace string `json:"namespace,omitempty"`
+	Distance  int    `json:"distance"`
+	From      string `json:"from"` // nó pelo qual a falha chegou
+	Via       string `json:"via"`  // tipo da aresta (ou ViaContains)
+}
+
+// impactForward são as arestas em que o alvo depende da origem (a falha
+// segue o sentido da aresta). Nas demais, a origem depende do alvo (Service
+// depende dos Pods que seleciona, Pod depende do Node, do PVC, do ConfigMap...).
+var impactForward = map[string]bool{
+	EdgeOwns: true, // sem o dono, os filhos deixam de existir
+}
+
+// impactIgnored são arestas que não transmitem falha: allowed-ingress
+// descreve tráfego permitido e templates não amarra o ciclo de vida dos PVCs.
+var impactIgnored = map[string]bool{
+	EdgeAllowedIngress: true,
+	EdgeTemplates:      true,
+}
+
+// ValidateDirection verifica o parâmetro direction recebido pela API.
+func ValidateDirection(direction string) error {
+	switch direction {
+	case DirectionDown, DirectionUp, DirectionBoth:
+		return nil
+	}
+	return fmt.Errorf("direction deve ser up, down ou both")
+}
+
+// hop é um passo da travessia: o vizinho e a aresta usada.
+type hop struct {
+	to  string
+	via string
+}
+
+// walk faz uma busca em largura a partir de root até depth passos (depth <= 0
+// = sem limite) e devolve a distância e o passo de chegada de cada nó.
+func walk(root string, depth int, next func(id string) []hop) (map[string]int, map[string]ImpactedNode) {
+	dist := map[string]int{root: 0}
+	reached := map[string]ImpactedNode{}
+	queue := []string{root}
+	for len(queue) > 0 {
+		id := queue[0]
+		queue = queue[1:]
+		if depth > 0 && dist[id] >= depth {
+			continue
+		}
+		for _, h := range next(id) {
+			if _, seen := dist[h.to]; seen {
+				continue
+			}
+			dist[h.to] = dist[id] + 1
+			reached[h.to] = ImpactedNode{ID: h.to, Distance: dist[h.to], From: id, Via: h.via}
+			queue = append(queue, h.to)
+		}
+	}
+	return dist, reached
+}
+
+// Neighborhood recorta o grafo aos nós alcançáveis a partir de root em até
+// depth passos (depth <= 0 = sem limite), seguindo as arestas na direção
+// pedida. Cada nó recebe data.distance. Retorna ErrNotFound se root não existe.
+func Neighborhood(g *ClusterGraph, root string, depth int, direction string) (*ClusterGraph, error) {
+	if err := ValidateDirection(direction); err != nil {
+		return nil, err
+	}
+	if !hasNode(g, root) {
+		return nil, fmt.Errorf("nó %s: %w", root, ErrNotFound)
+	}
+
+	out := map[string][]hop{}
+	in := map[string][]hop{}
+	for _, e := range g.Edges {
+		out[e.Source] = append(out[e.Source], hop{to: e.Target, via: e.Type})
+		in[e.Target] = append(in[e.Target], hop{to: e.Source, via: e.Type})
+	}
+	dist, _ := walk(root, depth, func(id string) []hop {
+		switch direction {
+		case DirectionDown:
+			return out[id]
+		case DirectionUp:
+			return in[id]
+		}
+		return append(append([]hop{}, out[id]...), in[id]...)
+	})
+
+	return withDistances(g, dist), nil
+}
+
+// Impact calcula o raio de impacto de uma falha em root: tudo que depende dela,
+// direta ou indiretamente (Node -> Pods -> Services -> Ingresses, ConfigMap ->
+// workloads...). Grupos (zonas, regiões, namespaces) afetam os nós que contêm.
+// Retorna o subgrafo afetado e a lista ordenada por distância.
+func Impact(g *ClusterGraph, root string, depth int) (*ClusterGraph, []ImpactedNode, error) {
+	if !hasNode(g, root) {
+		return nil, nil, fmt.Errorf("nó %s: %w", root, ErrNotFound)
+	}
+
+	dependents := map[string][]hop{}
+	for _, e := range g.Edges {
+		switch {
+		case impactIgnored[e.Type]:
+		case impactForward[e.Type]:
+			dependents[e.Source] = append(dependents[e.Source], hop{to: e.Target, via: e.Type})
+		default:
+			dependents[e.Target] = append(dependents[e.Target], hop{to: e.Source, via: e.Type})
+		}
+	}
+	for _, n := range g.Nodes {
+		if n.Parent != "" {
+			dependents[n.Parent] = append(dependents[n.Parent], hop{to: n.ID, via: ViaContains})
+		}
+	}
+
+	dist, reached := walk(root, depth, func(id string) []hop { return dependents[id] })
+
+	affected := make([]ImpactedNode, 0, len(reached))
+	for _, n := range g.Nodes {
+		r, ok := reached[n.ID]
+		if !ok || n.Group {
+			continue
+		}
+		r.Kind, r.Name, r.Namespace = n.Kind, n.Name, n.Namespace
+		affected = append(affected, r)
+	}
+	sort.SliceStable(affected, func(i, j int) bool {
+		if affected[i].Distance != affected[j].Distance {
+			return affected[i].Distance < affected[j].Distance
+		}
+		return affected[i].ID < affected[j].ID
+	})
+
+	sub := withDistances(g, dist)
+	for i := range sub.Nodes {
+		if r, ok := reached[sub.Nodes[i].ID]; ok {
+			sub.Nodes[i].Data["impactVia"] = r.Via
+		}
+	}
+	return sub, affected, nil
+}
+
+func hasNode(g *ClusterGraph, id string) bool {
+	for _, n := range g.Nodes {
+		if n.ID == id {
+			return true
+		}
+	}
+	return false
+}
+
+// withDistances recorta g aos nós de dist (mais os grupos pais) e grava
+// data.distance em cada um; os grupos trazidos só como contêiner ficam sem.
+func withDistances(g *ClusterGraph, dist map[string]int) *ClusterGraph {
+	keep := make(map[string]bool, len(dist))
+	for id := range dist {
+		keep[id] = true
+	}
+	sub := subgraph(g, keep)
+	for i := range sub.Nodes {
+		n := &sub.Nodes[i]
+		d, ok := dist[n.ID]
+		if !ok {
+			continue
+		}
+		data := make(map[string]interface{}, len(n.Data)+1)
+		for k, v := range n.Data {
+			data[k] = v
+		}
+		data["distance"] = d
+		n.Data = data
+	}
+	return sub
+}
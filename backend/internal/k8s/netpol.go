@@ -0,0 +1,613 @@
+package k8s
+
+import (
+	"fmt"
+	"net"
+	"sort"
+	"strconv"
+
+	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/util/intstr"
+)
+
+/*
+========================
+ NETWORKPOLICY (ANÁLISE DE TRÁFEGO PERMITIDO)
+========================
+*/
+
+// PolicyVerdict é a decisão de um dos lados (egress da origem ou ingress do destino).
+type PolicyVerdict struct {
+	Isolated bool     `json:"isolated"`           // alguma policy seleciona o pod para esta direção
+	Allowed  bool     `json:"allowed"`            // tráfego permitido nesta direção
+	Policies []string `json:"policies,omitempty"` // ns/nome das policies que decidiram
+	Reason   string   `json:"reason"`
+}
+
+// ReachabilityResult responde "o pod A alcança o pod B na porta P?".
+type ReachabilityResult struct {
+	From     string        `json:"from"`
+	To       string        `json:"to"`
+	Port     string        `json:"port,omitempty"`
+	Protocol string        `json:"protocol,omitempty"`
+	Allowed  bool          `json:"allowed"`
+	Egress   PolicyVerdict `json:"egress"`
+	Ingress  PolicyVerdict `json:"ingress"`
+	Ports    []string      `json:"ports,omitempty"`    // sem porta: portas permitidas nas duas direções
+	Reason   string        `json:"reason,omitempty"`   // quando as duas direções passam, mas em portas diferentes
+	Warnings []string      `json:"warnings,omitempty"` // policies com seletores inválidos
+}
+
+// policyEvaluator avalia NetworkPolicies contra os pods e namespaces carregados.
+// Seletores são compilados uma vez por build; os inválidos viram avisos.
+type policyEvaluator struct {
+	policies []*compiledPolicy
+	nsLabels map[string]labels.Set
+	warnings []TopologyWarning
+}
+
+type compiledPolicy struct {
+	key          string // ns/nome
+	namespace    string
+	podSelector  labels.Selector
+	ingress      bool
+	egress       bool
+	ingressRules []compiledRule
+	egressRules  []compiledRule
+}
+
+type compiledRule struct {
+	peers []compiledPeer // vazio = qualquer origem/destino
+	ports []networkingv1.NetworkPolicyPort
+}
+
+type compiledPeer struct {
+	podSelector labels.Selector // nil = todos os pods dos namespaces selecionados
+	nsSelector  labels.Selector // nil = apenas o namespace da policy
+	ipBlock     *networkingv1.IPBlock
+	invalid     bool // seletor inválido: nunca casa, para não ampliar o tráfego permitido
+}
+
+func newPolicyEvaluator(res *clusterResources) *policyEvaluator {
+	ev := &policyEvaluator{nsLabels: make(map[string]labels.Set, len(res.Namespaces))}
+	for _, ns := range res.Namespaces {
+		ev.nsLabels[ns.Name] = labels.Set(ns.Labels)
+	}
+
+	for _, np := range res.NetworkPolicies {
+		key := np.Namespace + "/" + np.Name
+		warn := func(err error, consequence string) {
+			ev.warnings = append(ev.warnings, TopologyWarning{
+				Kind:    "NetworkPolicy",
+				Scope:   np.Namespace,
+				Class:   WarningError,
+				Message: fmt.Sprintf("NetworkPolicy %s: seletor inválido (%v); %s", key, err, consequence),
+			})
+		}
+
+		podSel, err := metav1.LabelSelectorAsSelector(&np.Spec.PodSelector)
+		if err != nil {
+			warn(err, "policy ignorada, o tráfego permitido pode estar superestimado")
+			continue
+		}
+		cp := &compiledPolicy{key: key, namespace: np.Namespace, podSelector: podSel}
+
+		// Sem policyTypes: Ingress sempre, Egress só se houver regras de egress
+		if len(np.Spec.PolicyTypes) == 0 {
+			cp.ingress = true
+			cp.egress = len(np.Spec.Egress) > 0
+		}
+		for _, t := range np.Spec.PolicyTypes {
+			switch t {
+			case networkingv1.PolicyTypeIngress:
+				cp.ingress = true
+			case networkingv1.PolicyTypeEgress:
+				cp.egress = true
+			}
+		}
+		for _, r := range np.Spec.Ingress {
+			peers, err := compilePeers(r.From)
+			if err != nil {
+				warn(err, "o peer não casa com nenhum pod")
+			}
+			cp.ingressRules = append(cp.ingressRules, compiledRule{peers: peers, ports: r.Ports})
+		}
+		for _, r := range np.Spec.Egress {
+			peers, err := compilePeers(r.To)
+			if err != nil {
+				warn(err, "o peer não casa com nenhum pod")
+			}
+			cp.egressRules = append(cp.egressRules, compiledRule{peers: peers, ports: r.Ports})
+		}
+		ev.policies = append(ev.policies, cp)
+	}
+	return ev
+}
+
+// compilePeers compila os peers de uma regra. Um peer com seletor inválido
+// continua na lista marcado como invalid: descartá-lo deixaria a regra sem
+// peers, o que significa "qualquer origem/destino". Retorna o primeiro erro.
+func compilePeers(peers []networkingv1.NetworkPolicyPeer) ([]compiledPeer, error) {
+	out := make([]compiledPeer, 0, len(peers))
+	var firstErr error
+	for _, p := range peers {
+		cp := compiledPeer{ipBlock: p.IPBlock}
+		var err error
+		if p.PodSelector != nil {
+			if cp.podSelector, err = metav1.LabelSelectorAsSelector(p.PodSelector); err != nil {
+				cp.invalid = true
+			}
+		}
+		if p.NamespaceSelector != nil && !cp.invalid {
+			if cp.nsSelector, err = metav1.LabelSelectorAsSelector(p.NamespaceSelector); err != nil {
+				cp.invalid = true
+			}
+		}
+		if err != nil && firstErr == nil {
+			firstErr = err
+		}
+		out = append(out, cp)
+	}
+	return out, firstErr
+}
+
+// selecting retorna as policies que selecionam o pod na direção informada.
+func (ev *policyEvaluator) selecting(pod *corev1.Pod, ingress bool) []*compiledPolicy {
+	out := []*compiledPolicy{}
+	for _, p := range ev.policies {
+		if p.namespace != pod.Namespace || !p.podSelector.Matches(labels.Set(pod.Labels)) {
+			continue
+		}
+		if (ingress && p.ingress) || (!ingress && p.egress) {
+			out = append(out, p)
+		}
+	}
+	return out
+}
+
+// peerMatches verifica se o pod "other" é coberto por um peer de uma policy do namespace policyNS.
+func (ev *policyEvaluator) peerMatches(peer compiledPeer, policyNS string, other *corev1.Pod) bool {
+	if peer.invalid {
+		return false
+	}
+	if peer.ipBlock != nil {
+		return ipBlockMatches(peer.ipBlock, other.Status.PodIP)
+	}
+	if peer.nsSelector == nil {
+		if other.Namespace != policyNS {
+			return false
+		}
+	} else if !peer.nsSelector.Matches(ev.nsLabels[other.Namespace]) {
+		return false
+	}
+	return peer.podSelector == nil || peer.podSelector.Matches(labels.Set(other.Labels))
+}
+
+func ipBlockMatches(block *networkingv1.IPBlock, podIP string) bool {
+	ip := net.ParseIP(podIP)
+	if ip == nil {
+		return false
+	}
+	_, cidr, err := net.ParseCIDR(block.CIDR)
+	if err != nil || !cidr.Contains(ip) {
+		return false
+	}
+	for _, except := range block.Except {
+		if _, ex, err := net.ParseCIDR(except); err == nil && ex.Contains(ip) {
+			return false
+		}
+	}
+	return true
+}
+
+// trafficPort é a porta de destino já resolvida contra os containers do pod de destino.
+// any=true significa "qualquer porta" (usado nas arestas do grafo) e é
+// avaliado por evaluate sobre portas candidatas; unlisted é uma porta do
+// protocolo que nenhuma regra cita, coberta só por regras sem porta.
+type trafficPort struct {
+	any      bool
+	unlisted bool
+	number   int32
+	name     string
+	protocol corev1.Protocol
+}
+
+// policyProtocols são os protocolos aceitos em NetworkPolicyPort.
+var policyProtocols = []corev1.Protocol{corev1.ProtocolTCP, corev1.ProtocolUDP, corev1.ProtocolSCTP}
+
+func resolvePort(dst *corev1.Pod, port intstr.IntOrString, protocol corev1.Protocol, anyPort bool) trafficPort {
+	tp := trafficPort{any: anyPort, protocol: protocol}
+	if anyPort {
+		return tp
+	}
+	if port.Type == intstr.String {
+		tp.name = port.StrVal
+	} else {
+		tp.number = port.IntVal
+	}
+	for _, c := range dst.Spec.Containers {
+		for _, cp := range c.Ports {
+			if cp.Protocol != "" && cp.Protocol != protocol {
+				continue
+			}
+			if tp.name != "" && cp.Name == tp.name {
+				tp.number = cp.ContainerPort
+			} else if tp.name == "" && cp.ContainerPort == tp.number {
+				tp.name = cp.Name
+			}
+		}
+	}
+	return tp
+}
+
+func policyPortProtocol(p networkingv1.NetworkPolicyPort) corev1.Protocol {
+	if p.Protocol != nil {
+		return *p.Protocol
+	}
+	return corev1.ProtocolTCP
+}
+
+// policyPortMatches verifica se a porta de uma regra cobre tp (tp.any não chega aqui).
+func policyPortMatches(p networkingv1.NetworkPolicyPort, tp trafficPort) bool {
+	if policyPortProtocol(p) != tp.protocol {
+		return false
+	}
+	if p.Port == nil {
+		return true
+	}
+	if tp.unlisted {
+		return false
+	}
+	if p.Port.Type == intstr.String {
+		return tp.name != "" && p.Port.StrVal == tp.name
+	}
+	end := p.Port.IntVal
+	if p.EndPort != nil {
+		end = *p.EndPort
+	}
+	return tp.number != 0 && tp.number >= p.Port.IntVal && tp.number <= end
+}
+
+func (r compiledRule) portMatches(tp trafficPort) bool {
+	if len(r.ports) == 0 || tp.any {
+		return true
+	}
+	for _, p := range r.ports {
+		if policyPortMatches(p, tp) {
+			return true
+		}
+	}
+	return false
+}
+
+// formatPolicyPort descreve uma porta de regra para exibição ("TCP/8080", "TCP/*").
+func formatPolicyPort(p networkingv1.NetworkPolicyPort) string {
+	port := "*"
+	if p.Port != nil {
+		port = p.Port.String()
+		if p.EndPort != nil {
+			port += "-" + strconv.Itoa(int(*p.EndPort))
+		}
+	}
+	return string(policyPortProtocol(p)) + "/" + port
+}
+
+// matchingPorts descreve as portas da regra que cobrem tp ("any" para regra sem portas).
+func (r compiledRule) matchingPorts(tp trafficPort) []string {
+	if len(r.ports) == 0 {
+		return []string{"any"}
+	}
+	out := []string{}
+	for _, p := range r.ports {
+		if tp.any || policyPortMatches(p, tp) {
+			out = append(out, formatPolicyPort(p))
+		}
+	}
+	return out
+}
+
+// verdict avalia uma direção. Para ingress, "self" é o destino e "peer" a
+// origem; para egress, o contrário. O pod de destino define as portas nomeadas.
+// Retorna também as portas das regras que permitiram o tráfego.
+func (ev *policyEvaluator) verdict(self, peer *corev1.Pod, tp trafficPort, ingress bool) (PolicyVerdict, []string) {
+	selecting := ev.selecting(self, ingress)
+	if len(selecting) == 0 {
+		return PolicyVerdict{Allowed: true, Reason: "nenhuma NetworkPolicy seleciona o pod"}, []string{"any"}
+	}
+
+	v := PolicyVerdict{Isolated: true}
+	ports := map[string]bool{}
+	for _, p := range selecting {
+		rules := p.egressRules
+		if ingress {
+			rules = p.ingressRules
+		}
+		for _, r := range rules {
+			if !r.portMatches(tp) {
+				continue
+			}
+			matched := len(r.peers) == 0
+			for _, peerSel := range r.peers {
+				if ev.peerMatches(peerSel, p.namespace, peer) {
+					matched = true
+					break
+				}
+			}
+			if matched {
+				v.Allowed = true
+				v.Policies = appendUnique(v.Policies, p.key)
+				for _, port := range r.matchingPorts(tp) {
+					ports[port] = true
+				}
+			}
+		}
+	}
+
+	if v.Allowed {
+		v.Reason = "permitido por regra de NetworkPolicy"
+		return v, sortedKeys(ports)
+	}
+	for _, p := range selecting {
+		v.Policies = append(v.Policies, p.key)
+	}
+	v.Reason = "pod isolado e nenhuma regra permite o tráfego"
+	return v, nil
+}
+
+// portCandidates lista as portas concretas que representam "qualquer porta"
+// de src para dst: uma porta não citada por protocolo, as portas dos
+// containers de dst e as portas (início e fim de intervalos) citadas pelas
+// regras de egress de src e de ingress de dst. Se alguma porta passa nas duas
+// direções, uma destas passa.
+func (ev *policyEvaluator) portCandidates(src, dst *corev1.Pod) []trafficPort {
+	var out []trafficPort
+	seen := map[string]bool{}
+	add := func(proto corev1.Protocol, number int32) {
+		key := string(proto) + "/" + strconv.Itoa(int(number))
+		if number == 0 || seen[key] {
+			return
+		}
+		seen[key] = true
+		out = append(out, resolvePort(dst, intstr.FromInt32(number), proto, false))
+	}
+
+	for _, proto := range policyProtocols {
+		out = append(out, trafficPort{unlisted: true, protocol: proto})
+	}
+	for _, c := range dst.Spec.Containers {
+		for _, cp := range c.Ports {
+			proto := cp.Protocol
+			if proto == "" {
+				proto = corev1.ProtocolTCP
+			}
+			add(proto, cp.ContainerPort)
+		}
+	}
+	addRules := func(policies []*compiledPolicy, ingress bool) {
+		for _, p := range policies {
+			rules := p.egressRules
+			if ingress {
+				rules = p.ingressRules
+			}
+			for _, r := range rules {
+				for _, pp := range r.ports {
+					if pp.Port == nil || pp.Port.Type == intstr.String {
+						continue // nomeadas só casam com portas dos containers de dst
+					}
+					add(policyPortProtocol(pp), pp.Port.IntVal)
+					if pp.EndPort != nil {
+						add(policyPortProtocol(pp), *pp.EndPort)
+					}
+				}
+			}
+		}
+	}
+	addRules(ev.selecting(src, false), false)
+	addRules(ev.selecting(dst, true), true)
+	return out
+}
+
+// evaluate decide se src alcança dst (egress da origem e ingress do destino).
+// Com tp.any, as duas direções precisam permitir a mesma porta: as portas
+// retornadas são a interseção (uma direção sem restrição no protocolo mostra
+// as portas da outra).
+func (ev *policyEvaluator) evaluate(src, dst *corev1.Pod, tp trafficPort) (*ReachabilityResult, []string) {
+	result := &ReachabilityResult{
+		From:     src.Namespace + "/" + src.Name,
+		To:       dst.Namespace + "/" + dst.Name,
+		Protocol: string(tp.protocol),
+	}
+	if !tp.any {
+		egress, _ := ev.verdict(src, dst, tp, false)
+		ingress, ports := ev.verdict(dst, src, tp, true)
+		result.Egress, result.Ingress = egress, ingress
+		result.Allowed = egress.Allowed && ingress.Allowed
+		return result, ports
+	}
+
+	// Direções isoladas, para o caso de nenhuma porta passar nas duas
+	result.Egress, _ = ev.verdict(src, dst, tp, false)
+	result.Ingress, _ = ev.verdict(dst, src, tp, true)
+	if !result.Egress.Allowed || !result.Ingress.Allowed {
+		return result, nil
+	}
+
+	ports := map[string]bool{}
+	wideEgress := map[corev1.Protocol]bool{}
+	wideIngress := map[corev1.Protocol]bool{}
+	var egress, ingress PolicyVerdict
+	for _, c := range ev.portCandidates(src, dst) {
+		eg, egPorts := ev.verdict(src, dst, c, false)
+		in, inPorts := ev.verdict(dst, src, c, true)
+		if c.unlisted {
+			wideEgress[c.protocol], wideIngress[c.protocol] = eg.Allowed, in.Allowed
+		}
+		if !eg.Allowed || !in.Allowed {
+			continue
+		}
+		if !result.Allowed {
+			egress, ingress = eg, in
+			result.Allowed = true
+		}
+		for _, key := range eg.Policies {
+			egress.Policies = appendUnique(egress.Policies, key)
+		}
+		for _, key := range in.Policies {
+			ingress.Policies = appendUnique(ingress.Policies, key)
+		}
+
+		var labels []string
+		switch {
+		case c.unlisted:
+			labels = []string{string(c.protocol) + "/*"}
+		case wideEgress[c.protocol]:
+			labels = inPorts
+		case wideIngress[c.protocol]:
+			labels = egPorts
+		default:
+			labels = []string{string(c.protocol) + "/" + strconv.Itoa(int(c.number))}
+		}
+		for _, l := range labels {
+			if l == "any" {
+				l = string(c.protocol) + "/*"
+			}
+			ports[l] = true
+		}
+	}
+
+	if !result.Allowed {
+		result.Reason = "egress e ingress não permitem nenhuma porta em comum"
+		return result, nil
+	}
+	result.Egress, result.Ingress = egress, ingress
+	if len(ports) == len(policyProtocols) && ports["TCP/*"] && ports["UDP/*"] && ports["SCTP/*"] {
+		return result, []string{"any"}
+	}
+	return result, sortedKeys(ports)
+}
+
+// evaluateReachability responde se o pod src alcança o pod dst na porta/protocolo
+// informados. Porta vazia significa "em alguma porta" de qualquer protocolo, e
+// Ports lista as portas em que as duas direções permitem o tráfego.
+func evaluateReachability(res *clusterResources, src, dst *corev1.Pod, port string, protocol corev1.Protocol) *ReachabilityResult {
+	if protocol == "" {
+		protocol = corev1.ProtocolTCP
+	}
+	ev := newPolicyEvaluator(res)
+	tp := resolvePort(dst, intstr.Parse(port), protocol, port == "")
+	result, ports := ev.evaluate(src, dst, tp)
+	result.Port = port
+	if tp.any {
+		result.Protocol = "" // qualquer porta de qualquer protocolo
+		result.Ports = ports
+	}
+	for _, w := range ev.warnings {
+		result.Warnings = append(result.Warnings, w.Message)
+	}
+	return result
+}
+
+// addNetworkPolicyGraph marca o isolamento de cada pod e cria arestas
+// allowed-ingress entre workloads. Para não gerar O(W²) arestas, só entram
+// destinos com ingress isolado: sem policy, todo tráfego é permitido e a
+// aresta não acrescentaria informação. Cada workload é representado pelo
+// primeiro pod (em ordem de nome) — réplicas compartilham labels e portas.
+func addNetworkPolicyGraph(g *ClusterGraph, res *clusterResources) {
+	ev := newPolicyEvaluator(res)
+	owners := newOwnerIndex(res)
+	if len(ev.warnings) > 0 {
+		g.Warnings = append(g.Warnings, ev.warnings...)
+		sortWarnings(g.Warnings)
+	}
+
+	// Status de isolamento por pod
+	podStatus := make(map[string]map[string]interface{}, len(res.Pods))
+	for _, pod := range res.Pods {
+		ing := ev.selecting(pod, true)
+		eg := ev.selecting(pod, false)
+		policies := []string{}
+		for _, p := range append(ing, eg...) {
+			policies = appendUnique(policies, p.key)
+		}
+		sort.Strings(policies)
+		podStatus["pod:"+pod.Namespace+":"+pod.Name] = map[string]interface{}{
+			"ingressIsolated": len(ing) > 0,
+			"egressIsolated":  len(eg) > 0,
+			"policies":        policies,
+		}
+	}
+	for i := range g.Nodes {
+		if st, ok := podStatus[g.Nodes[i].ID]; ok {
+			if g.Nodes[i].Data == nil {
+				g.Nodes[i].Data = map[string]interface{}{}
+			}
+			g.Nodes[i].Data["networkPolicy"] = st
+		}
+	}
+
+	// Representante de cada workload
+	reps := map[string]*corev1.Pod{}
+	order := []string{}
+	for _, pod := range res.Pods {
+		if pod.Status.Phase == corev1.PodSucceeded || pod.Status.Phase == corev1.PodFailed {
+			continue
+		}
+		id, ok := owners.workloadID(pod)
+		if !ok {
+			id = "pod:" + pod.Namespace + ":" + pod.Name
+		}
+		if _, exists := reps[id]; !exists {
+			reps[id] = pod
+			order = append(order, id)
+		}
+	}
+
+	anyPort := trafficPort{any: true, protocol: corev1.ProtocolTCP}
+	for _, dstID := range order {
+		dst := reps[dstID]
+		if len(ev.selecting(dst, true)) == 0 {
+			continue
+		}
+		for _, srcID := range order {
+			if srcID == dstID {
+				continue
+			}
+			result, ports := ev.evaluate(reps[srcID], dst, anyPort)
+			if !result.Allowed {
+				continue
+			}
+			g.Edges = append(g.Edges, GraphEdge{
+				ID:     "edge:netpol:" + srcID + "->" + dstID,
+				Source: srcID,
+				Target: dstID,
+				Type:   EdgeAllowedIngress,
+				Data: map[string]interface{}{
+					"policies": result.Ingress.Policies,
+					"ports":    ports,
+				},
+			})
+		}
+	}
+}
+
+// findPod localiza um pod carregado por namespace/nome.
+func findPod(res *clusterResources, ns, name string) (*corev1.Pod, error) {
+	for _, p := range res.Pods {
+		if p.Namespace == ns && p.Name == name {
+			return p, nil
+		}
+	}
+	return nil, fmt.Errorf("pod %s/%s: %w", ns, name, ErrNotFound)
+}
+
+func appendUnique(list []string, v string) []string {
+	for _, s := range list {
+		if s == v {
+			return list
+		}
+	}
+	return append(list, v)
+}
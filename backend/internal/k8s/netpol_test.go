@@ -0,0 +1,277 @@
+package k8s
+
+import (
+	"reflect"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+)
+
+func testPod(ns, name, ip string, labels map[string]string, ports ...corev1.ContainerPort) *corev1.Pod {
+	return &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Namespace: ns, Name: name, Labels: labels},
+		Spec:       corev1.PodSpec{Containers: []corev1.Container{{Name: "app", Ports: ports}}},
+		Status:     corev1.PodStatus{PodIP: ip},
+	}
+}
+
+func testPolicy(ns, name string, spec networkingv1.NetworkPolicySpec) *networkingv1.NetworkPolicy {
+	return &networkingv1.NetworkPolicy{ObjectMeta: metav1.ObjectMeta{Namespace: ns, Name: name}, Spec: spec}
+}
+
+func TestEvaluateReachability(t *testing.T) {
+	tcp := corev1.ProtocolTCP
+	udp := corev1.ProtocolUDP
+	port := func(p intstr.IntOrString) *intstr.IntOrString { return &p }
+	endPort := func(p int32) *int32 { return &p }
+
+	web := testPod("shop", "web", "10.0.1.10", map[string]string{"app": "web"})
+	api := testPod("shop", "api", "10.0.2.20", map[string]string{"app": "api"},
+		corev1.ContainerPort{Name: "http", ContainerPort: 8080},
+		corev1.ContainerPort{Name: "metrics", ContainerPort: 9090},
+	)
+	apiSelector := metav1.LabelSelector{MatchLabels: map[string]string{"app": "api"}}
+	webPeer := networkingv1.NetworkPolicyPeer{PodSelector: &metav1.LabelSelector{MatchLabels: map[string]string{"app": "web"}}}
+
+	tests := []struct {
+		name         string
+		policies     []*networkingv1.NetworkPolicy
+		port         string
+		protocol     corev1.Protocol
+		allowed      bool
+		egressIso    bool
+		ingressIso   bool
+		ports        []string // só conferido quando informado
+		wantWarnings bool
+	}{
+		{
+			name:    "sem policies tudo é permitido",
+			port:    "8080",
+			allowed: true,
+		},
+		{
+			name: "sem policyTypes isola só ingress quando não há regras de egress",
+			policies: []*networkingv1.NetworkPolicy{
+				testPolicy("shop", "deny-all", networkingv1.NetworkPolicySpec{PodSelector: metav1.LabelSelector{}}),
+			},
+			port:       "8080",
+			allowed:    false,
+			ingressIso: true,
+		},
+		{
+			name: "sem policyTypes isola egress quando há regras de egress",
+			policies: []*networkingv1.NetworkPolicy{
+				testPolicy("shop", "web-egress", networkingv1.NetworkPolicySpec{
+					PodSelector: metav1.LabelSelector{MatchLabels: map[string]string{"app": "web"}},
+					Egress: []networkingv1.NetworkPolicyEgressRule{{
+						Ports: []networkingv1.NetworkPolicyPort{{Protocol: &tcp, Port: port(intstr.FromInt32(53))}},
+					}},
+				}),
+			},
+			port:       "8080",
+			allowed:    false,
+			egressIso:  true,
+			ingressIso: false,
+		},
+		{
+			name: "policyTypes só Egress não isola ingress",
+			policies: []*networkingv1.NetworkPolicy{
+				testPolicy("shop", "api-egress", networkingv1.NetworkPolicySpec{
+					PodSelector: apiSelector,
+					PolicyTypes: []networkingv1.PolicyType{networkingv1.PolicyTypeEgress},
+				}),
+			},
+			port:    "8080",
+			allowed: true,
+		},
+		{
+			name: "porta nomeada casa com a porta do container",
+			policies: []*networkingv1.NetworkPolicy{
+				testPolicy("shop", "api-http", networkingv1.NetworkPolicySpec{
+					PodSelector: apiSelector,
+					Ingress: []networkingv1.NetworkPolicyIngressRule{{
+						From:  []networkingv1.NetworkPolicyPeer{webPeer},
+						Ports: []networkingv1.NetworkPolicyPort{{Port: port(intstr.FromString("http"))}},
+					}},
+				}),
+			},
+			port:       "8080",
+			allowed:    true,
+			ingressIso: true,
+		},
+		{
+			name: "porta nomeada não casa com outra porta",
+			policies: []*networkingv1.NetworkPolicy{
+				testPolicy("shop", "api-http", networkingv1.NetworkPolicySpec{
+					PodSelector: apiSelector,
+					Ingress: []networkingv1.NetworkPolicyIngressRule{{
+						From:  []networkingv1.NetworkPolicyPeer{webPeer},
+						Ports: []networkingv1.NetworkPolicyPort{{Port: port(intstr.FromString("http"))}},
+					}},
+				}),
+			},
+			port:       "9090",
+			allowed:    false,
+			ingressIso: true,
+		},
+		{
+			name: "endPort inclui o intervalo",
+			policies: []*networkingv1.NetworkPolicy{
+				testPolicy("shop", "api-range", networkingv1.NetworkPolicySpec{
+					PodSelector: apiSelector,
+					Ingress: []networkingv1.NetworkPolicyIngressRule{{
+						Ports: []networkingv1.NetworkPolicyPort{{Port: port(intstr.FromInt32(8000)), EndPort: endPort(8100)}},
+					}},
+				}),
+			},
+			port:       "8080",
+			allowed:    true,
+			ingressIso: true,
+		},
+		{
+			name: "endPort exclui o que está fora do intervalo",
+			policies: []*networkingv1.NetworkPolicy{
+				testPolicy("shop", "api-range", networkingv1.NetworkPolicySpec{
+					PodSelector: apiSelector,
+					Ingress: []networkingv1.NetworkPolicyIngressRule{{
+						Ports: []networkingv1.NetworkPolicyPort{{Port: port(intstr.FromInt32(8000)), EndPort: endPort(8079)}},
+					}},
+				}),
+			},
+			port:       "8080",
+			allowed:    false,
+			ingressIso: true,
+		},
+		{
+			name: "protocolo diferente do da regra",
+			policies: []*networkingv1.NetworkPolicy{
+				testPolicy("shop", "api-udp", networkingv1.NetworkPolicySpec{
+					PodSelector: apiSelector,
+					Ingress: []networkingv1.NetworkPolicyIngressRule{{
+						Ports: []networkingv1.NetworkPolicyPort{{Protocol: &udp, Port: port(intstr.FromInt32(8080))}},
+					}},
+				}),
+			},
+			port:       "8080",
+			allowed:    false,
+			ingressIso: true,
+		},
+		{
+			name: "ipBlock permite o IP da origem",
+			policies: []*networkingv1.NetworkPolicy{
+				testPolicy("shop", "api-cidr", networkingv1.NetworkPolicySpec{
+					PodSelector: apiSelector,
+					Ingress: []networkingv1.NetworkPolicyIngressRule{{
+						From: []networkingv1.NetworkPolicyPeer{{IPBlock: &networkingv1.IPBlock{CIDR: "10.0.0.0/16"}}},
+					}},
+				}),
+			},
+			port:       "8080",
+			allowed:    true,
+			ingressIso: true,
+		},
+		{
+			name: "ipBlock except remove o IP da origem",
+			policies: []*networkingv1.NetworkPolicy{
+				testPolicy("shop", "api-cidr", networkingv1.NetworkPolicySpec{
+					PodSelector: apiSelector,
+					Ingress: []networkingv1.NetworkPolicyIngressRule{{
+						From: []networkingv1.NetworkPolicyPeer{{IPBlock: &networkingv1.IPBlock{CIDR: "10.0.0.0/16", Except: []string{"10.0.1.0/24"}}}},
+					}},
+				}),
+			},
+			port:       "8080",
+			allowed:    false,
+			ingressIso: true,
+		},
+		{
+			name: "sem porta, egress e ingress sem porta em comum",
+			policies: []*networkingv1.NetworkPolicy{
+				testPolicy("shop", "web-dns", networkingv1.NetworkPolicySpec{
+					PodSelector: metav1.LabelSelector{MatchLabels: map[string]string{"app": "web"}},
+					PolicyTypes: []networkingv1.PolicyType{networkingv1.PolicyTypeEgress},
+					Egress: []networkingv1.NetworkPolicyEgressRule{{
+						Ports: []networkingv1.NetworkPolicyPort{{Protocol: &udp, Port: port(intstr.FromInt32(53))}},
+					}},
+				}),
+				testPolicy("shop", "api-http", networkingv1.NetworkPolicySpec{
+					PodSelector: apiSelector,
+					Ingress: []networkingv1.NetworkPolicyIngressRule{{
+						Ports: []networkingv1.NetworkPolicyPort{{Protocol: &tcp, Port: port(intstr.FromInt32(8080))}},
+					}},
+				}),
+			},
+			allowed:    false,
+			egressIso:  true,
+			ingressIso: true,
+		},
+		{
+			name: "sem porta, egress em intervalo e ingress por nome",
+			policies: []*networkingv1.NetworkPolicy{
+				testPolicy("shop", "web-range", networkingv1.NetworkPolicySpec{
+					PodSelector: metav1.LabelSelector{MatchLabels: map[string]string{"app": "web"}},
+					PolicyTypes: []networkingv1.PolicyType{networkingv1.PolicyTypeEgress},
+					Egress: []networkingv1.NetworkPolicyEgressRule{{
+						Ports: []networkingv1.NetworkPolicyPort{{Port: port(intstr.FromInt32(8000)), EndPort: endPort(8100)}},
+					}},
+				}),
+				testPolicy("shop", "api-http", networkingv1.NetworkPolicySpec{
+					PodSelector: apiSelector,
+					Ingress: []networkingv1.NetworkPolicyIngressRule{{
+						Ports: []networkingv1.NetworkPolicyPort{{Port: port(intstr.FromString("http"))}},
+					}},
+				}),
+			},
+			allowed:    true,
+			egressIso:  true,
+			ingressIso: true,
+			ports:      []string{"TCP/8080"},
+		},
+		{
+			name: "peer com seletor inválido não vira permite-tudo",
+			policies: []*networkingv1.NetworkPolicy{
+				testPolicy("shop", "api-broken", networkingv1.NetworkPolicySpec{
+					PodSelector: apiSelector,
+					Ingress: []networkingv1.NetworkPolicyIngressRule{{
+						From: []networkingv1.NetworkPolicyPeer{{PodSelector: &metav1.LabelSelector{
+							MatchExpressions: []metav1.LabelSelectorRequirement{{Key: "app", Operator: "Bogus"}},
+						}}},
+					}},
+				}),
+			},
+			port:         "8080",
+			allowed:      false,
+			ingressIso:   true,
+			wantWarnings: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			res := &clusterResources{
+				Pods:            []*corev1.Pod{web, api},
+				NetworkPolicies: tt.policies,
+				Namespaces:      []*corev1.Namespace{{ObjectMeta: metav1.ObjectMeta{Name: "shop"}}},
+			}
+			got := evaluateReachability(res, web, api, tt.port, tt.protocol)
+			if got.Allowed != tt.allowed {
+				t.Errorf("Allowed = %v, want %v (egress: %s, ingress: %s)", got.Allowed, tt.allowed, got.Egress.Reason, got.Ingress.Reason)
+			}
+			if got.Egress.Isolated != tt.egressIso {
+				t.Errorf("Egress.Isolated = %v, want %v", got.Egress.Isolated, tt.egressIso)
+			}
+			if got.Ingress.Isolated != tt.ingressIso {
+				t.Errorf("Ingress.Isolated = %v, want %v", got.Ingress.Isolated, tt.ingressIso)
+			}
+			if tt.ports != nil && !reflect.DeepEqual(got.Ports, tt.ports) {
+				t.Errorf("Ports = %v, want %v", got.Ports, tt.ports)
+			}
+			if (len(got.Warnings) > 0) != tt.wantWarnings {
+				t.Errorf("Warnings = %v, want warnings: %v", got.Warnings, tt.wantWarnings)
+			}
+		})
+	}
+}
@@ -0,0 +1,289 @@
+package k8s
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/discovery"
+)
+
+/*
+========================
+ GRAFO GENÉRICO DE OWNER REFERENCES (DISCOVERY + DYNAMIC CLIENT)
+========================
+*/
+
+// As arestas owns fixas (Deployment -> ReplicaSet -> Pod, StatefulSet -> Pod...)
+// não enxergam controllers de CRDs (Argo Rollouts, operators de banco...). Com
+// TopologyOptions.OwnerRefs, os recursos configurados no cluster (GVRs escolhidos
+// pelo admin, ver models.Cluster.OwnerResources) são lidos pelo dynamic client,
+// viram nós e todas as ownerReferences são resolvidas por UID.
+
+// ownerResource é um GVR configurado já resolvido pelo discovery.
+type ownerResource struct {
+	GVR        schema.GroupVersionResource
+	Kind       string
+	Namespaced bool
+}
+
+// APIResourceInfo descreve um recurso listável do cluster, para o admin
+// escolher o que entra no grafo de owner references.
+type APIResourceInfo struct {
+	Resource   string `json:"resource"` // formato aceito por ParseGVR
+	Kind       string `json:"kind"`
+	Namespaced bool   `json:"namespaced"`
+	BuiltIn    bool   `json:"builtIn"` // já desenhado pelo grafo fixo
+}
+
+// builtInKinds são os kinds que o grafo já desenha com tipos do client-go; se
+// configurados, são ignorados (as ownerReferences deles entram de qualquer forma).
+var builtInKinds = map[schema.GroupKind]bool{
+	{Group: "apps", Kind: "Deployment"}:                     true,
+	{Group: "apps", Kind: "StatefulSet"}:                    true,
+	{Group: "apps", Kind: "DaemonSet"}:                      true,
+	{Group: "apps", Kind: "ReplicaSet"}:                     true,
+	{Group: "", Kind: "Pod"}:                                true,
+	{Group: "", Kind: "Service"}:                            true,
+	{Group: "", Kind: "Node"}:                               true,
+	{Group: "", Kind: "Namespace"}:                          true,
+	{Group: "", Kind: "ConfigMap"}:                          true,
+	{Group: "", Kind: "Secret"}:                             true,
+	{Group: "", Kind: "PersistentVolumeClaim"}:              true,
+	{Group: "", Kind: "PersistentVolume"}:                   true,
+	{Group: "storage.k8s.io", Kind: "StorageClass"}:         true,
+	{Group: "batch", Kind: "Job"}:                           true,
+	{Group: "batch", Kind: "CronJob"}:                       true,
+	{Group: "autoscaling", Kind: "HorizontalPodAutoscaler"}: true,
+	{Group: "networking.k8s.io", Kind: "Ingress"}:           true,
+	{Group: "networking.k8s.io", Kind: "NetworkPolicy"}:     true,
+	{Group: "discovery.k8s.io", Kind: "EndpointSlice"}:      true,
+	{Group: gatewayAPIGroup, Kind: "Gateway"}:               true,
+	{Group: gatewayAPIGroup, Kind: "HTTPRoute"}:             true,
+}
+
+// ParseGVR lê um recurso no formato "grupo/versão/recurso" (ex:
+// "argoproj.io/v1alpha1/rollouts") ou "versão/recurso" para o grupo core.
+func ParseGVR(s string) (schema.GroupVersionResource, error) {
+	parts := strings.Split(strings.TrimSpace(s), "/")
+	for _, p := range parts {
+		if p == "" {
+			return schema.GroupVersionResource{}, fmt.Errorf("recurso inválido: %q (use grupo/versão/recurso)", s)
+		}
+	}
+	switch len(parts) {
+	case 2:
+		return schema.GroupVersionResource{Version: parts[0], Resource: parts[1]}, nil
+	case 3:
+		return schema.GroupVersionResource{Group: parts[0], Version: parts[1], Resource: parts[2]}, nil
+	}
+	return schema.GroupVersionResource{}, fmt.Errorf("recurso inválido: %q (use grupo/versão/recurso)", s)
+}
+
+// ParseGVRList lê a lista de recursos separada por vírgulas de
+// models.Cluster.OwnerResources.
+func ParseGVRList(list string) ([]schema.GroupVersionResource, error) {
+	var out []schema.GroupVersionResource
+	for _, s := range strings.Split(list, ",") {
+		if strings.TrimSpace(s) == "" {
+			continue
+		}
+		gvr, err := ParseGVR(s)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, gvr)
+	}
+	return out, nil
+}
+
+// formatGVR é o inverso de ParseGVR.
+func formatGVR(gvr schema.GroupVersionResource) string {
+	if gvr.Group == "" {
+		return gvr.Version + "/" + gvr.Resource
+	}
+	return gvr.Group + "/" + gvr.Version + "/" + gvr.Resource
+}
+
+// resolveOwnerResources confere os GVRs configurados no discovery: descarta os
+// que o cluster não serve, os que não aceitam list/watch e os kinds fixos do grafo.
+func resolveOwnerResources(disc discovery.DiscoveryInterface, gvrs []schema.GroupVersionResource) ([]ownerResource, []error) {
+	var out []ownerResource
+	var errs []error
+	for _, gvr := range gvrs {
+		list, err := disc.ServerResourcesForGroupVersion(gvr.GroupVersion().String())
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", formatGVR(gvr), err))
+			continue
+		}
+		var found *metav1.APIResource
+		for i := range list.APIResources {
+			if list.APIResources[i].Name == gvr.Resource {
+				found = &list.APIResources[i]
+				break
+			}
+		}
+		switch {
+		case found == nil:
+			errs = append(errs, fmt.Errorf("%s: recurso não servido pelo cluster", formatGVR(gvr)))
+		case !listable(found):
+			errs = append(errs, fmt.Errorf("%s: recurso não aceita list/watch", formatGVR(gvr)))
+		case builtInKinds[schema.GroupKind{Group: gvr.Group, Kind: found.Kind}]:
+			errs = append(errs, fmt.Errorf("%s: kind %s já faz parte do grafo", formatGVR(gvr), found.Kind))
+		default:
+			out = append(out, ownerResource{GVR: gvr, Kind: found.Kind, Namespaced: found.Namespaced})
+		}
+	}
+	return out, errs
+}
+
+func listable(r *metav1.APIResource) bool {
+	if strings.Contains(r.Name, "/") { // subrecursos (status, scale...)
+		return false
+	}
+	var list, watch bool
+	for _, verb := range r.Verbs {
+		list = list || verb == "list"
+		watch = watch || verb == "watch"
+	}
+	return list && watch
+}
+
+// DiscoverAPIResources lista os recursos listáveis do cluster (versão preferida
+// de cada grupo), em ordem de recurso. Grupos com discovery falho são ignorados.
+func DiscoverAPIResources(disc discovery.DiscoveryInterface) ([]APIResourceInfo, error) {
+	lists, err := disc.ServerPreferredResources()
+	if len(lists) == 0 && err != nil {
+		return nil, err
+	}
+	var out []APIResourceInfo
+	for _, list := range lists {
+		gv, perr := schema.ParseGroupVersion(list.GroupVersion)
+		if perr != nil {
+			continue
+		}
+		for i := range list.APIResources {
+			r := &list.APIResources[i]
+			if !listable(r) {
+				continue
+			}
+			out = append(out, APIResourceInfo{
+				Resource:   formatGVR(gv.WithResource(r.Name)),
+				Kind:       r.Kind,
+				Namespaced: r.Namespaced,
+				BuiltIn:    builtInKinds[schema.GroupKind{Group: gv.Group, Kind: r.Kind}],
+			})
+		}
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Resource < out[j].Resource })
+	return out, nil
+}
+
+// genericNodeID é o ID do nó de um objeto lido pelo dynamic client, com o grupo
+// no prefixo para não colidir com os kinds fixos (ex: "rollout.argoproj.io:ns:web").
+func genericNodeID(u *unstructured.Unstructured) string {
+	gvk := u.GroupVersionKind()
+	prefix := strings.ToLower(gvk.Kind)
+	if gvk.Group != "" {
+		prefix += "." + gvk.Group
+	}
+	return prefix + ":" + u.GetNamespace() + ":" + u.GetName()
+}
+
+// graphObject é um objeto do cluster e o ID do seu nó no grafo.
+type graphObject struct {
+	id  string
+	obj metav1.Object
+}
+
+// graphObjects lista os objetos de res que viram nós, com os IDs usados em
+// buildClusterGraph (o nó pode não existir, ex: ConfigMap não referenciado).
+func graphObjects(res *clusterResources) []graphObject {
+	var objects []graphObject
+	add := func(prefix string, objs []metav1.Object) {
+		for _, obj := range objs {
+			id := prefix + obj.GetNamespace() + ":" + obj.GetName()
+			if prefix == "node:" || prefix == "pv:" {
+				id = prefix + obj.GetName()
+			}
+			objects = append(objects, graphObject{id: id, obj: obj})
+		}
+	}
+	add("deploy:", asObjects(res.Deployments))
+	add("sts:", asObjects(res.StatefulSets))
+	add("ds:", asObjects(res.DaemonSets))
+	add("rs:", asObjects(res.ReplicaSets))
+	add("pod:", asObjects(res.Pods))
+	add("svc:", asObjects(res.Services))
+	add("hpa:", asObjects(res.HPAs))
+	add("node:", asObjects(res.Nodes))
+	add("job:", asObjects(res.Jobs))
+	add("cronjob:", asObjects(res.CronJobs))
+	add("ing:", asObjects(res.Ingresses))
+	add("gw:", asObjects(res.Gateways))
+	add("httproute:", asObjects(res.HTTPRoutes))
+	add("pvc:", asObjects(res.PVCs))
+	add("pv:", asObjects(res.PVs))
+	add("cm:", asObjects(res.ConfigMaps))
+	add("secret:", asObjects(res.Secrets))
+	for _, u := range res.Generic {
+		objects = append(objects, graphObject{id: genericNodeID(u), obj: u})
+	}
+	return objects
+}
+
+// addOwnerRefGraph adiciona os objetos genéricos como nós e resolve por UID as
+// ownerReferences de todos os objetos do grafo. Arestas owns já criadas pelo
+// grafo fixo não são duplicadas; as novas levam data.ownerRef e data.controller.
+func addOwnerRefGraph(g *ClusterGraph, res *clusterResources) {
+	for _, u := range res.Generic {
+		g.Nodes = append(g.Nodes, GraphNode{
+			ID: genericNodeID(u), Kind: u.GetKind(), Name: u.GetName(), Namespace: u.GetNamespace(), Labels: u.GetLabels(),
+			Data: map[string]interface{}{"apiVersion": u.GetAPIVersion()},
+		})
+	}
+
+	inGraph := make(map[string]bool, len(g.Nodes))
+	for _, n := range g.Nodes {
+		inGraph[n.ID] = true
+	}
+	objects := graphObjects(res)
+
+	byUID := make(map[types.UID]string, len(objects))
+	for _, o := range objects {
+		if inGraph[o.id] && o.obj.GetUID() != "" {
+			byUID[o.obj.GetUID()] = o.id
+		}
+	}
+
+	existing := map[string]bool{}
+	for _, e := range g.Edges {
+		if e.Type == EdgeOwns {
+			existing[e.Source+"->"+e.Target] = true
+		}
+	}
+
+	for _, o := range objects {
+		if !inGraph[o.id] {
+			continue
+		}
+		for _, ref := range o.obj.GetOwnerReferences() {
+			owner, ok := byUID[ref.UID]
+			if !ok || existing[owner+"->"+o.id] {
+				continue
+			}
+			existing[owner+"->"+o.id] = true
+			g.Edges = append(g.Edges, GraphEdge{
+				ID:     "edge:ownerref:" + owner + "->" + o.id,
+				Source: owner,
+				Target: o.id,
+				Type:   EdgeOwns,
+				Data:   map[string]interface{}{"ownerRef": true, "controller": ref.Controller != nil && *ref.Controller},
+			})
+		}
+	}
+}
@@ -0,0 +1,73 @@
+package k8s
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// ownerIndex resolve o workload de topo de um pod, seguindo as ownerReferences
+// (Pod -> ReplicaSet -> Deployment, Pod -> StatefulSet, Pod -> DaemonSet,
+// Pod -> Job -> CronJob).
+type ownerIndex struct {
+	rsOwner  map[string]string // ns/replicaset -> deployment
+	jobOwner map[string]string // ns/job -> cronjob
+}
+
+func newOwnerIndex(res *clusterResources) *ownerIndex {
+	idx := &ownerIndex{
+		rsOwner:  make(map[string]string, len(res.ReplicaSets)),
+		jobOwner: make(map[string]string, len(res.Jobs)),
+	}
+	for _, rs := range res.ReplicaSets {
+		if ref := controllerRef(rs.OwnerReferences); ref != nil && ref.Kind == "Deployment" {
+			idx.rsOwner[rs.Namespace+"/"+rs.Name] = ref.Name
+		}
+	}
+	for _, job := range res.Jobs {
+		if ref := controllerRef(job.OwnerReferences); ref != nil && ref.Kind == "CronJob" {
+			idx.jobOwner[job.Namespace+"/"+job.Name] = ref.Name
+		}
+	}
+	return idx
+}
+
+// workloadID retorna o ID do nó do workload dono do pod. ok=false para pods
+// sem controller (ex: pods estáticos ou criados manualmente).
+func (o *ownerIndex) workloadID(pod *corev1.Pod) (string, bool) {
+	ref := controllerRef(pod.OwnerReferences)
+	if ref == nil {
+		return "", false
+	}
+	ns := pod.Namespace
+	switch ref.Kind {
+	case "ReplicaSet":
+		if dep, ok := o.rsOwner[ns+"/"+ref.Name]; ok {
+			return "deploy:" + ns + ":" + dep, true
+		}
+		return "rs:" + ns + ":" + ref.Name, true
+	case "StatefulSet":
+		return "sts:" + ns + ":" + ref.Name, true
+	case "DaemonSet":
+		return "ds:" + ns + ":" + ref.Name, true
+	case "Job":
+		if cj, ok := o.jobOwner[ns+"/"+ref.Name]; ok {
+			return "cronjob:" + ns + ":" + cj, true
+		}
+		return "job:" + ns + ":" + ref.Name, true
+	}
+	return "", false
+}
+
+// controllerRef retorna a ownerReference marcada como controller, ou a primeira
+// se nenhuma estiver marcada.
+func controllerRef(refs []metav1.OwnerReference) *metav1.OwnerReference {
+	for i := range refs {
+		if refs[i].Controller != nil && *refs[i].Controller {
+			return &refs[i]
+		}
+	}
+	if len(refs) > 0 {
+		return &refs[0]
+	}
+	return nil
+}
@@ -34,6 +34,10 @@ func GetResourceYAML(ctx context.Context, client *kubernetes.Clientset, ns, kind
 		obj, err = client.AppsV1().DaemonSets(ns).Get(ctx, name, metav1.GetOptions{})
 	case "ReplicaSet":
 		obj, err = client.AppsV1().ReplicaSets(ns).Get(ctx, name, metav1.GetOptions{})
+	case "Job":
+		obj, err = client.BatchV1().Jobs(ns).Get(ctx, name, metav1.GetOptions{})
+	case "CronJob":
+		obj, err = client.BatchV1().CronJobs(ns).Get(ctx, name, metav1.GetOptions{})
 	case "Namespace":
 		obj, err = client.CoreV1().Namespaces().Get(ctx, name, metav1.GetOptions{})
 	case "Node":
@@ -0,0 +1,186 @@
+package k8s
+
+import (
+	"encoding/json"
+	"sort"
+	"sync"
+)
+
+/*
+========================
+ REVISÕES E DELTAS DO GRAFO
+========================
+*/
+
+const (
+	revisionsPerKey = 8  // revisões guardadas por combinação de opções
+	revisionMaxKeys = 32 // combinações de opções (namespace, layout...) guardadas por cluster
+)
+
+// RFGraphDelta é a resposta de ?since=<revision>. Com Full=false, o cliente
+// aplica Removed*, depois Added* e substitui os Changed* (mesmo ID) para chegar
+// em Revision. Com Full=true a revisão pedida não está mais disponível e Graph
+// traz o grafo completo.
+type RFGraphDelta struct {
+	Since    uint64 `json:"since"`
+	Revision uint64 `json:"revision"`
+	Full     bool   `json:"full"`
+
+	Graph *RFGraph `json:"graph,omitempty"`
+
+	AddedNodes   []RFNode `json:"addedNodes,omitempty"`
+	RemovedNodes []string `json:"removedNodes,omitempty"`
+	ChangedNodes []RFNode `json:"changedNodes,omitempty"`
+	AddedEdges   []RFEdge `json:"addedEdges,omitempty"`
+	RemovedEdges []string `json:"removedEdges,omitempty"`
+	ChangedEdges []RFEdge `json:"changedEdges,omitempty"`
+
+	Warnings []TopologyWarning `json:"warnings"`
+}
+
+// graphRevision guarda o JSON de cada nó/aresta para comparação barata.
+type graphRevision struct {
+	rev   uint64
+	nodes map[string]string
+	edges map[string]string
+	order []string // IDs de nós na ordem do grafo (pais antes dos filhos)
+}
+
+// revisionStore numera os grafos construídos por um ClusterCache. Builds
+// idênticos ao último da mesma combinação de opções mantêm a revisão, então
+// um polling sem mudanças recebe sempre o mesmo número e deltas vazios.
+type revisionStore struct {
+	mu    sync.Mutex
+	last  uint64
+	byKey map[string][]*graphRevision
+}
+
+func newRevisionStore() *revisionStore {
+	return &revisionStore{byKey: make(map[string][]*graphRevision)}
+}
+
+// optionsKey identifica a combinação de opções: revisões de opções diferentes
+// não são comparáveis (outro namespace, outro layout...).
+func optionsKey(opts TopologyOptions) string {
+	b, _ := json.Marshal(opts)
+	return string(b)
+}
+
+// record atribui a revisão de g (reaproveitando a última se nada mudou).
+func (s *revisionStore) record(key string, g *RFGraph) {
+	cur := snapshotRevision(g)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	history := s.byKey[key]
+	if n := len(history); n > 0 && sameRevision(history[n-1], cur) {
+		g.Revision = history[n-1].rev
+		return
+	}
+
+	s.last++
+	cur.rev = s.last
+	g.Revision = cur.rev
+	history = append(history, cur)
+	if len(history) > revisionsPerKey {
+		history = history[len(history)-revisionsPerKey:]
+	}
+	s.byKey[key] = history
+
+	// Descarta a combinação de opções usada há mais tempo
+	if len(s.byKey) > revisionMaxKeys {
+		oldestKey, oldestRev := "", s.last
+		for k, h := range s.byKey {
+			if r := h[len(h)-1].rev; r < oldestRev {
+				oldestKey, oldestRev = k, r
+			}
+		}
+		delete(s.byKey, oldestKey)
+	}
+}
+
+func (s *revisionStore) get(key string, rev uint64) *graphRevision {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, r := range s.byKey[key] {
+		if r.rev == rev {
+			return r
+		}
+	}
+	return nil
+}
+
+func snapshotRevision(g *RFGraph) *graphRevision {
+	r := &graphRevision{
+		nodes: make(map[string]string, len(g.Nodes)),
+		edges: make(map[string]string, len(g.Edges)),
+		order: make([]string, 0, len(g.Nodes)),
+	}
+	for _, n := range g.Nodes {
+		b, _ := json.Marshal(n)
+		r.nodes[n.ID] = string(b)
+		r.order = append(r.order, n.ID)
+	}
+	for _, e := range g.Edges {
+		b, _ := json.Marshal(e)
+		r.edges[e.ID] = string(b)
+	}
+	return r
+}
+
+func sameRevision(a, b *graphRevision) bool {
+	if len(a.nodes) != len(b.nodes) || len(a.edges) != len(b.edges) {
+		return false
+	}
+	for id, v := range b.nodes {
+		if a.nodes[id] != v {
+			return false
+		}
+	}
+	for id, v := range b.edges {
+		if a.edges[id] != v {
+			return false
+		}
+	}
+	return true
+}
+
+// diffGraph calcula o delta de prev até g. Nós adicionados seguem a ordem do
+// grafo atual, então pais chegam antes dos filhos, como exige o React Flow.
+func diffGraph(prev *graphRevision, g *RFGraph) *RFGraphDelta {
+	cur := snapshotRevision(g)
+	d := &RFGraphDelta{Since: prev.rev, Revision: g.Revision, Warnings: g.Warnings}
+
+	for _, n := range g.Nodes {
+		old, ok := prev.nodes[n.ID]
+		switch {
+		case !ok:
+			d.AddedNodes = append(d.AddedNodes, n)
+		case old != cur.nodes[n.ID]:
+			d.ChangedNodes = append(d.ChangedNodes, n)
+		}
+	}
+	for _, id := range prev.order {
+		if _, ok := cur.nodes[id]; !ok {
+			d.RemovedNodes = append(d.RemovedNodes, id)
+		}
+	}
+
+	for _, e := range g.Edges {
+		old, ok := prev.edges[e.ID]
+		switch {
+		case !ok:
+			d.AddedEdges = append(d.AddedEdges, e)
+		case old != cur.edges[e.ID]:
+			d.ChangedEdges = append(d.ChangedEdges, e)
+		}
+	}
+	for id := range prev.edges {
+		if _, ok := cur.edges[id]; !ok {
+			d.RemovedEdges = append(d.RemovedEdges, id)
+		}
+	}
+	sort.Strings(d.RemovedEdges)
+	return d
+}
@@ -0,0 +1,298 @@
+package k8s
+
+import (
+	"strconv"
+	"strings"
+
+	networkingv1 "k8s.io/api/networking/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/discovery"
+)
+
+/*
+========================
+ INGRESS & GATEWAY API
+========================
+*/
+
+const gatewayAPIGroup = "gateway.networking.k8s.io"
+
+// gatewayObject é o subconjunto de gateway.networking.k8s.io/Gateway usado no grafo.
+// A Gateway API não tem tipos no client-go, então os objetos chegam como
+// unstructured e são convertidos para estas structs.
+type gatewayObject struct {
+	metav1.ObjectMeta `json:"metadata"`
+	Spec              struct {
+		GatewayClassName string `json:"gatewayClassName"`
+		Listeners        []struct {
+			Name     string  `json:"name"`
+			Hostname *string `json:"hostname,omitempty"`
+			Port     int32   `json:"port"`
+			Protocol string  `json:"protocol"`
+		} `json:"listeners"`
+	} `json:"spec"`
+}
+
+// httpRouteObject é o subconjunto de gateway.networking.k8s.io/HTTPRoute usado no grafo.
+type httpRouteObject struct {
+	metav1.ObjectMeta `json:"metadata"`
+	Spec              struct {
+		ParentRefs []gatewayObjectRef `json:"parentRefs"`
+		Hostnames  []string           `json:"hostnames"`
+		Rules      []struct {
+			Matches []struct {
+				Path *struct {
+					Type  string `json:"type"`
+					Value string `json:"value"`
+				} `json:"path,omitempty"`
+			} `json:"matches"`
+			BackendRefs []gatewayObjectRef `json:"backendRefs"`
+		} `json:"rules"`
+	} `json:"spec"`
+}
+
+// gatewayObjectRef cobre tanto parentRefs quanto backendRefs.
+type gatewayObjectRef struct {
+	Group     *string `json:"group,omitempty"`
+	Kind      *string `json:"kind,omitempty"`
+	Namespace *string `json:"namespace,omitempty"`
+	Name      string  `json:"name"`
+	Port      *int32  `json:"port,omitempty"`
+}
+
+// refersTo indica se a referência aponta para group/kind (com os defaults da API).
+func (r gatewayObjectRef) refersTo(group, kind string) bool {
+	g, k := group, kind
+	if r.Group != nil {
+		g = *r.Group
+	}
+	if r.Kind != nil {
+		k = *r.Kind
+	}
+	return g == group && k == kind
+}
+
+func (r gatewayObjectRef) namespaceOr(def string) string {
+	if r.Namespace != nil && *r.Namespace != "" {
+		return *r.Namespace
+	}
+	return def
+}
+
+// gatewayAPIResources descobre quais versões da Gateway API estão instaladas.
+// Retorna ok=false quando as CRDs não existem no cluster.
+func gatewayAPIResources(disc discovery.DiscoveryInterface) (gateways, routes schema.GroupVersionResource, ok bool) {
+	for _, version := range []string{"v1", "v1beta1"} {
+		list, err := disc.ServerResourcesForGroupVersion(gatewayAPIGroup + "/" + version)
+		if err != nil {
+			continue
+		}
+		var hasGw, hasRoute bool
+		for _, r := range list.APIResources {
+			switch r.Name {
+			case "gateways":
+				hasGw = true
+			case "httproutes":
+				hasRoute = true
+			}
+		}
+		if hasGw && hasRoute {
+			gateways = schema.GroupVersionResource{Group: gatewayAPIGroup, Version: version, Resource: "gateways"}
+			routes = schema.GroupVersionResource{Group: gatewayAPIGroup, Version: version, Resource: "httproutes"}
+			return gateways, routes, true
+		}
+	}
+	return gateways, routes, false
+}
+
+// convertUnstructured converte objetos unstructured para structs locais,
+// descartando os que não puderem ser convertidos.
+func convertUnstructured[T any](objs []runtime.Object) []*T {
+	out := make([]*T, 0, len(objs))
+	for _, obj := range objs {
+		u, ok := obj.(*unstructured.Unstructured)
+		if !ok {
+			continue
+		}
+		var typed T
+		if err := runtime.DefaultUnstructuredConverter.FromUnstructured(u.UnstructuredContent(), &typed); err == nil {
+			out = append(out, &typed)
+		}
+	}
+	return out
+}
+
+// addRoutingGraph adiciona nós de Ingress/Gateway/HTTPRoute e as arestas de roteamento até os Services.
+func addRoutingGraph(g *ClusterGraph, res *clusterResources) {
+	for _, ing := range res.Ingresses {
+		ingID := "ing:" + ing.Namespace + ":" + ing.Name
+		g.Nodes = append(g.Nodes, GraphNode{
+			ID: ingID, Kind: "Ingress", Name: ing.Name, Namespace: ing.Namespace, Labels: ing.Labels,
+			Data: ingressData(ing),
+		})
+
+		seen := map[string]bool{}
+		addRoute := func(backend *networkingv1.IngressServiceBackend, host, path string) {
+			if backend == nil {
+				return
+			}
+			id := "edge:ing->svc:" + ing.Namespace + ":" + ing.Name + "->" + backend.Name + ":" + host + path
+			if seen[id] {
+				return
+			}
+			seen[id] = true
+			data := map[string]interface{}{"host": host, "path": path}
+			if port := ingressBackendPort(backend.Port); port != "" {
+				data["port"] = port
+			}
+			if host == "" && path == "" {
+				data["default"] = true
+			}
+			g.Edges = append(g.Edges, GraphEdge{
+				ID:     id,
+				Source: ingID,
+				Target: "svc:" + ing.Namespace + ":" + backend.Name,
+				Type:   EdgeRoutes,
+				Label:  routeLabel(host, path),
+				Data:   data,
+			})
+		}
+
+		if ing.Spec.DefaultBackend != nil {
+			addRoute(ing.Spec.DefaultBackend.Service, "", "")
+		}
+		for _, rule := range ing.Spec.Rules {
+			if rule.HTTP == nil {
+				continue
+			}
+			for _, p := range rule.HTTP.Paths {
+				path := p.Path
+				if path == "" {
+					path = "/"
+				}
+				addRoute(p.Backend.Service, rule.Host, path)
+			}
+		}
+	}
+
+	for _, gw := range res.Gateways {
+		listeners := []string{}
+		for _, l := range gw.Spec.Listeners {
+			listener := l.Protocol + "/" + strconv.Itoa(int(l.Port))
+			if l.Hostname != nil && *l.Hostname != "" {
+				listener = *l.Hostname + " " + listener
+			}
+			listeners = append(listeners, listener)
+		}
+		g.Nodes = append(g.Nodes, GraphNode{
+			ID: "gw:" + gw.Namespace + ":" + gw.Name, Kind: "Gateway", Name: gw.Name, Namespace: gw.Namespace, Labels: gw.Labels,
+			Data: map[string]interface{}{"gatewayClassName": gw.Spec.GatewayClassName, "listeners": listeners},
+		})
+	}
+
+	for _, route := range res.HTTPRoutes {
+		routeID := "httproute:" + route.Namespace + ":" + route.Name
+		g.Nodes = append(g.Nodes, GraphNode{
+			ID: routeID, Kind: "HTTPRoute", Name: route.Name, Namespace: route.Namespace, Labels: route.Labels,
+			Data: map[string]interface{}{"hostnames": route.Spec.Hostnames},
+		})
+
+		host := strings.Join(route.Spec.Hostnames, ",")
+
+		// Gateway -> HTTPRoute (o Gateway pode estar em outro namespace)
+		for _, parent := range route.Spec.ParentRefs {
+			if !parent.refersTo(gatewayAPIGroup, "Gateway") {
+				continue
+			}
+			gwNS := parent.namespaceOr(route.Namespace)
+			g.Edges = append(g.Edges, GraphEdge{
+				ID:     "edge:gw->httproute:" + gwNS + ":" + parent.Name + "->" + route.Namespace + ":" + route.Name,
+				Source: "gw:" + gwNS + ":" + parent.Name,
+				Target: routeID,
+				Type:   EdgeRoutes,
+				Label:  host,
+				Data:   map[string]interface{}{"host": host},
+			})
+		}
+
+		// HTTPRoute -> Service, uma aresta por par (path, backend)
+		seen := map[string]bool{}
+		for _, rule := range route.Spec.Rules {
+			paths := []string{}
+			for _, m := range rule.Matches {
+				if m.Path != nil && m.Path.Value != "" {
+					paths = append(paths, m.Path.Value)
+				}
+			}
+			if len(paths) == 0 {
+				paths = []string{"/"}
+			}
+			for _, backend := range rule.BackendRefs {
+				if !backend.refersTo("", "Service") {
+					continue
+				}
+				svcNS := backend.namespaceOr(route.Namespace)
+				for _, path := range paths {
+					id := "edge:httproute->svc:" + route.Namespace + ":" + route.Name + "->" + svcNS + ":" + backend.Name + ":" + path
+					if seen[id] {
+						continue
+					}
+					seen[id] = true
+					data := map[string]interface{}{"host": host, "path": path}
+					if backend.Port != nil {
+						data["port"] = strconv.Itoa(int(*backend.Port))
+					}
+					g.Edges = append(g.Edges, GraphEdge{
+						ID:     id,
+						Source: routeID,
+						Target: "svc:" + svcNS + ":" + backend.Name,
+						Type:   EdgeRoutes,
+						Label:  routeLabel(host, path),
+						Data:   data,
+					})
+				}
+			}
+		}
+	}
+}
+
+func ingressData(ing *networkingv1.Ingress) map[string]interface{} {
+	hosts := []string{}
+	for _, rule := range ing.Spec.Rules {
+		if rule.Host != "" {
+			hosts = append(hosts, rule.Host)
+		}
+	}
+	data := map[string]interface{}{
+		"hosts": hosts,
+		"tls":   len(ing.Spec.TLS) > 0,
+	}
+	if ing.Spec.IngressClassName != nil {
+		data["ingressClassName"] = *ing.Spec.IngressClassName
+	}
+	return data
+}
+
+func ingressBackendPort(port networkingv1.ServiceBackendPort) string {
+	if port.Name != "" {
+		return port.Name
+	}
+	if port.Number != 0 {
+		return strconv.Itoa(int(port.Number))
+	}
+	return ""
+}
+
+// routeLabel monta o rótulo exibido na aresta (ex: "api.example.com/v1").
+func routeLabel(host, path string) string {
+	if host == "" && path == "" {
+		return "default"
+	}
+	if host == "" {
+		host = "*"
+	}
+	return host + path
+}
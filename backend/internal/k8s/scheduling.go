@@ -0,0 +1,112 @@
+package k8s
+
+import (
+	corev1 "k8s.io/api/core/v1"
+)
+
+/*
+========================
+ SCHEDULING (POD -> NODE) E ZONAS
+========================
+*/
+
+// addSchedulingGraph liga cada pod ao Node onde foi agendado e agrupa os Nodes
+// em grupos de zona/região (labels topology.kubernetes.io/*). Cada workload
+// recebe em data.zones as zonas onde seus pods estão, para evidenciar réplicas
+// concentradas em uma única zona.
+func addSchedulingGraph(g *ClusterGraph, res *clusterResources) {
+	zoneOfNode := make(map[string]string, len(res.Nodes))
+	regions := map[string]bool{}
+	zones := map[string]string{} // zona -> região
+	nodeCount := map[string]int{}
+
+	for _, n := range res.Nodes {
+		zone := n.Labels[corev1.LabelTopologyZone]
+		region := n.Labels[corev1.LabelTopologyRegion]
+		if region != "" {
+			regions[region] = true
+		}
+		if zone != "" {
+			zoneOfNode[n.Name] = zone
+			zones[zone] = region
+			nodeCount[zone]++
+		}
+	}
+
+	// Grupos de região e zona (zonas sem região ficam no topo)
+	for _, region := range sortedKeys(regions) {
+		g.Nodes = append(g.Nodes, GraphNode{
+			ID: "region:" + region, Kind: "Region", Name: region, Group: true,
+		})
+	}
+	podCount := map[string]int{}
+	for _, pod := range res.Pods {
+		if zone, ok := zoneOfNode[pod.Spec.NodeName]; ok {
+			podCount[zone]++
+		}
+	}
+	for _, zone := range sortedKeys(zones) {
+		node := GraphNode{
+			ID: "zone:" + zone, Kind: "Zone", Name: zone, Group: true,
+			Data: map[string]interface{}{"nodes": nodeCount[zone], "pods": podCount[zone]},
+		}
+		if region := zones[zone]; region != "" {
+			node.Parent = "region:" + region
+		}
+		g.Nodes = append(g.Nodes, node)
+	}
+	for i := range g.Nodes {
+		n := &g.Nodes[i]
+		if n.Kind != "Node" {
+			continue
+		}
+		if zone, ok := zoneOfNode[n.Name]; ok {
+			n.Parent = "zone:" + zone
+			if n.Data == nil {
+				n.Data = map[string]interface{}{}
+			}
+			n.Data["zone"] = zone
+			n.Data["region"] = zones[zone]
+		}
+	}
+
+	// Pod -> Node e distribuição de zonas por workload
+	owners := newOwnerIndex(res)
+	workloadZones := map[string]map[string]bool{}
+	for _, pod := range res.Pods {
+		if pod.Spec.NodeName == "" {
+			continue // ainda não agendado (Pending)
+		}
+		g.Edges = append(g.Edges, GraphEdge{
+			ID:     "edge:pod->node:" + pod.Namespace + ":" + pod.Name + "->" + pod.Spec.NodeName,
+			Source: "pod:" + pod.Namespace + ":" + pod.Name,
+			Target: "node:" + pod.Spec.NodeName,
+			Type:   EdgeScheduledOn,
+		})
+
+		zone, ok := zoneOfNode[pod.Spec.NodeName]
+		if !ok {
+			continue
+		}
+		if id, ok := owners.workloadID(pod); ok {
+			if workloadZones[id] == nil {
+				workloadZones[id] = map[string]bool{}
+			}
+			workloadZones[id][zone] = true
+		}
+	}
+
+	for i := range g.Nodes {
+		n := &g.Nodes[i]
+		zs, ok := workloadZones[n.ID]
+		if !ok {
+			continue
+		}
+		list := sortedKeys(zs)
+		if n.Data == nil {
+			n.Data = map[string]interface{}{}
+		}
+		n.Data["zones"] = list
+		n.Data["singleZone"] = len(list) == 1 && len(zones) > 1
+	}
+}
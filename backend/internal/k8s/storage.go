@@ -0,0 +1,185 @@
+package k8s
+
+import (
+	"strconv"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+)
+
+/*
+========================
+ STORAGE (PVC / PV / STORAGECLASS)
+========================
+*/
+
+const defaultStorageClassAnnotation = "storageclass.kubernetes.io/is-default-class"
+
+// addStorageGraph adiciona PVCs, PVs e StorageClasses com a cadeia
+// Pod -> PVC -> PV -> StorageClass e StatefulSet -> PVC (volumeClaimTemplates).
+func addStorageGraph(g *ClusterGraph, res *clusterResources) {
+	pvcByNs := make(map[string][]*corev1.PersistentVolumeClaim)
+	usedClasses := map[string]bool{}
+
+	for _, pvc := range res.PVCs {
+		pvcByNs[pvc.Namespace] = append(pvcByNs[pvc.Namespace], pvc)
+
+		pvcID := "pvc:" + pvc.Namespace + ":" + pvc.Name
+		g.Nodes = append(g.Nodes, GraphNode{
+			ID: pvcID, Kind: "PersistentVolumeClaim", Name: pvc.Name, Namespace: pvc.Namespace, Labels: pvc.Labels,
+			Data: pvcData(pvc),
+		})
+
+		className := pvcStorageClass(pvc)
+		if pvc.Spec.VolumeName != "" {
+			g.Edges = append(g.Edges, GraphEdge{
+				ID:     "edge:pvc->pv:" + pvc.Namespace + ":" + pvc.Name + "->" + pvc.Spec.VolumeName,
+				Source: pvcID,
+				Target: "pv:" + pvc.Spec.VolumeName,
+				Type:   EdgeBinds,
+			})
+		} else if className != "" {
+			// PVC ainda sem volume: liga direto na classe para mostrar quem deveria provisioná-lo
+			usedClasses[className] = true
+			g.Edges = append(g.Edges, GraphEdge{
+				ID:     "edge:pvc->sc:" + pvc.Namespace + ":" + pvc.Name + "->" + className,
+				Source: pvcID,
+				Target: "sc:" + className,
+				Type:   EdgeUsesClass,
+				Data:   map[string]interface{}{"pending": true},
+			})
+		}
+	}
+
+	// PVs e StorageClasses são cluster-scoped: com filtro de namespace só
+	// entram os que pertencem à cadeia dos PVCs carregados.
+	for _, pv := range res.PVs {
+		if res.scoped() && (pv.Spec.ClaimRef == nil || !res.inScope(pv.Spec.ClaimRef.Namespace)) {
+			continue
+		}
+		pvID := "pv:" + pv.Name
+		g.Nodes = append(g.Nodes, GraphNode{
+			ID: pvID, Kind: "PersistentVolume", Name: pv.Name, Labels: pv.Labels,
+			Data: pvData(pv),
+		})
+		if pv.Spec.StorageClassName != "" {
+			usedClasses[pv.Spec.StorageClassName] = true
+			g.Edges = append(g.Edges, GraphEdge{
+				ID:     "edge:pv->sc:" + pv.Name + "->" + pv.Spec.StorageClassName,
+				Source: pvID,
+				Target: "sc:" + pv.Spec.StorageClassName,
+				Type:   EdgeUsesClass,
+			})
+		}
+	}
+
+	for _, sc := range res.StorageClasses {
+		if res.scoped() && !usedClasses[sc.Name] {
+			continue
+		}
+		data := map[string]interface{}{
+			"provisioner": sc.Provisioner,
+			"isDefault":   sc.Annotations[defaultStorageClassAnnotation] == "true",
+		}
+		if sc.ReclaimPolicy != nil {
+			data["reclaimPolicy"] = string(*sc.ReclaimPolicy)
+		}
+		if sc.VolumeBindingMode != nil {
+			data["volumeBindingMode"] = string(*sc.VolumeBindingMode)
+		}
+		g.Nodes = append(g.Nodes, GraphNode{ID: "sc:" + sc.Name, Kind: "StorageClass", Name: sc.Name, Labels: sc.Labels, Data: data})
+	}
+
+	// Pod -> PVC (inclui volumes efêmeros, cujo PVC é <pod>-<volume>)
+	for _, pod := range res.Pods {
+		for _, vol := range pod.Spec.Volumes {
+			claim := ""
+			switch {
+			case vol.PersistentVolumeClaim != nil:
+				claim = vol.PersistentVolumeClaim.ClaimName
+			case vol.Ephemeral != nil:
+				claim = pod.Name + "-" + vol.Name
+			default:
+				continue
+			}
+			g.Edges = append(g.Edges, GraphEdge{
+				ID:     "edge:pod->pvc:" + pod.Namespace + ":" + pod.Name + "->" + claim,
+				Source: "pod:" + pod.Namespace + ":" + pod.Name,
+				Target: "pvc:" + pod.Namespace + ":" + claim,
+				Type:   EdgeMounts,
+				Data:   map[string]interface{}{"volume": vol.Name},
+			})
+		}
+	}
+
+	// StatefulSet -> PVCs gerados pelos volumeClaimTemplates (<template>-<sts>-<ordinal>)
+	for _, sts := range res.StatefulSets {
+		for _, tpl := range sts.Spec.VolumeClaimTemplates {
+			prefix := tpl.Name + "-" + sts.Name + "-"
+			for _, pvc := range pvcByNs[sts.Namespace] {
+				name := pvc.Name
+				ordinal, ok := strings.CutPrefix(name, prefix)
+				if !ok {
+					continue
+				}
+				if _, err := strconv.Atoi(ordinal); err != nil {
+					continue
+				}
+				g.Edges = append(g.Edges, GraphEdge{
+					ID:     "edge:sts->pvc:" + sts.Namespace + ":" + sts.Name + "->" + name,
+					Source: "sts:" + sts.Namespace + ":" + sts.Name,
+					Target: "pvc:" + sts.Namespace + ":" + name,
+					Type:   EdgeTemplates,
+					Data:   map[string]interface{}{"template": tpl.Name},
+				})
+			}
+		}
+	}
+}
+
+func pvcStorageClass(pvc *corev1.PersistentVolumeClaim) string {
+	if pvc.Spec.StorageClassName != nil {
+		return *pvc.Spec.StorageClassName
+	}
+	return ""
+}
+
+func pvcData(pvc *corev1.PersistentVolumeClaim) map[string]interface{} {
+	data := map[string]interface{}{
+		"phase":            string(pvc.Status.Phase),
+		"accessModes":      accessModes(pvc.Spec.AccessModes),
+		"storageClassName": pvcStorageClass(pvc),
+		"volumeName":       pvc.Spec.VolumeName,
+	}
+	if req, ok := pvc.Spec.Resources.Requests[corev1.ResourceStorage]; ok {
+		data["requested"] = req.String()
+	}
+	if capacity, ok := pvc.Status.Capacity[corev1.ResourceStorage]; ok {
+		data["capacity"] = capacity.String()
+	}
+	return data
+}
+
+func pvData(pv *corev1.PersistentVolume) map[string]interface{} {
+	data := map[string]interface{}{
+		"phase":            string(pv.Status.Phase),
+		"accessModes":      accessModes(pv.Spec.AccessModes),
+		"storageClassName": pv.Spec.StorageClassName,
+		"reclaimPolicy":    string(pv.Spec.PersistentVolumeReclaimPolicy),
+	}
+	if capacity, ok := pv.Spec.Capacity[corev1.ResourceStorage]; ok {
+		data["capacity"] = capacity.String()
+	}
+	if ref := pv.Spec.ClaimRef; ref != nil {
+		data["claim"] = ref.Namespace + "/" + ref.Name
+	}
+	return data
+}
+
+func accessModes(modes []corev1.PersistentVolumeAccessMode) []string {
+	out := make([]string, 0, len(modes))
+	for _, m := range modes {
+		out = append(out, string(m))
+	}
+	return out
+}
@@ -0,0 +1,86 @@
+package k8s
+
+/*
+========================
+ RENDERIZAÇÃO E RECORTES DE GRAFOS JÁ CONSTRUÍDOS
+========================
+*/
+
+// RenderGraph converte um grafo de domínio já construído (ex: lido de um
+// snapshot) para o formato do React Flow, com o layout escolhido.
+func RenderGraph(g *ClusterGraph, layoutName string) *RFGraph {
+	return toRFGraph(g, layoutName)
+}
+
+// FilterNamespace recorta o grafo para um namespace (ou uma lista "a,b"). Mantém
+// os nós dos namespaces (e seus grupos), os nós cluster-scoped ligados a eles
+// (Nodes, PVs, StorageClasses) e os grupos que os contêm (zonas/regiões).
+// Diferente de TopologyOptions.Namespace, Nodes sem pods do namespace ficam de fora.
+// "all" ou vazio retornam o próprio grafo.
+func FilterNamespace(g *ClusterGraph, ns string) *ClusterGraph {
+	nss := namespaceList(ns)
+	if len(nss) == 0 {
+		return g
+	}
+
+	inScope := map[string]bool{}
+	keep := map[string]bool{}
+	for _, name := range nss {
+		inScope[name] = true
+		keep["ns:"+name] = true
+	}
+	inNamespace := map[string]bool{}
+	clusterScoped := map[string]bool{}
+	kinds := make(map[string]string, len(g.Nodes))
+	for _, n := range g.Nodes {
+		kinds[n.ID] = n.Kind
+		switch {
+		case inScope[n.Namespace]:
+			keep[n.ID] = true
+			inNamespace[n.ID] = true
+		case n.Namespace == "" && !n.Group:
+			clusterScoped[n.ID] = true
+		}
+	}
+	// Cluster-scoped alcançáveis a partir do namespace, só para fora (Pod -> Node,
+	// PVC -> PV/StorageClass) e depois PV -> StorageClass. Nunca de um
+	// cluster-scoped de volta: a StorageClass levaria aos PVs de outros namespaces.
+	for _, e := range g.Edges {
+		if inNamespace[e.Source] && clusterScoped[e.Target] {
+			keep[e.Target] = true
+		}
+	}
+	for _, e := range g.Edges {
+		if keep[e.Source] && kinds[e.Source] == "PersistentVolume" && kinds[e.Target] == "StorageClass" {
+			keep[e.Target] = true
+		}
+	}
+	return subgraph(g, keep)
+}
+
+// subgraph copia os nós marcados (mais seus grupos pais, recursivamente) e as
+// arestas entre eles.
+func subgraph(g *ClusterGraph, keep map[string]bool) *ClusterGraph {
+	parentOf := make(map[string]string, len(g.Nodes))
+	for _, n := range g.Nodes {
+		parentOf[n.ID] = n.Parent
+	}
+	for id := range keep {
+		for p := parentOf[id]; p != "" && !keep[p]; p = parentOf[p] {
+			keep[p] = true
+		}
+	}
+
+	out := &ClusterGraph{Nodes: []GraphNode{}, Edges: []GraphEdge{}, Warnings: g.Warnings}
+	for _, n := range g.Nodes {
+		if keep[n.ID] {
+			out.Nodes = append(out.Nodes, n)
+		}
+	}
+	for _, e := range g.Edges {
+		if keep[e.Source] && keep[e.Target] {
+			out.Edges = append(out.Edges, e)
+		}
+	}
+	return out
+}
@@ -0,0 +1,58 @@
+package k8s
+
+import (
+	"sort"
+	"testing"
+)
+
+func TestFilterNamespaceSharedStorageClass(t *testing.T) {
+	g := &ClusterGraph{
+		Nodes: []GraphNode{
+			{ID: "ns:a", Kind: "Namespace", Name: "a", Group: true},
+			{ID: "ns:b", Kind: "Namespace", Name: "b", Group: true},
+			{ID: "pod:a:db-0", Kind: "Pod", Name: "db-0", Namespace: "a", Parent: "ns:a"},
+			{ID: "pvc:a:data", Kind: "PersistentVolumeClaim", Name: "data", Namespace: "a", Parent: "ns:a"},
+			{ID: "pvc:b:data", Kind: "PersistentVolumeClaim", Name: "data", Namespace: "b", Parent: "ns:b"},
+			{ID: "pv:pv-a", Kind: "PersistentVolume", Name: "pv-a"},
+			{ID: "pv:pv-b", Kind: "PersistentVolume", Name: "pv-b"},
+			{ID: "sc:standard", Kind: "StorageClass", Name: "standard"},
+			{ID: "node:n1", Kind: "Node", Name: "n1"},
+			{ID: "node:n2", Kind: "Node", Name: "n2"},
+		},
+		Edges: []GraphEdge{
+			{ID: "e1", Source: "pod:a:db-0", Target: "pvc:a:data", Type: EdgeMounts},
+			{ID: "e2", Source: "pvc:a:data", Target: "pv:pv-a", Type: EdgeBinds},
+			{ID: "e3", Source: "pvc:b:data", Target: "pv:pv-b", Type: EdgeBinds},
+			{ID: "e4", Source: "pv:pv-a", Target: "sc:standard", Type: EdgeUsesClass},
+			{ID: "e5", Source: "pv:pv-b", Target: "sc:standard", Type: EdgeUsesClass},
+			{ID: "e6", Source: "pvc:b:data", Target: "sc:standard", Type: EdgeUsesClass},
+			{ID: "e7", Source: "pod:a:db-0", Target: "node:n1", Type: EdgeScheduledOn},
+		},
+	}
+
+	out := FilterNamespace(g, "a")
+
+	var got []string
+	for _, n := range out.Nodes {
+		got = append(got, n.ID)
+	}
+	sort.Strings(got)
+	want := []string{"node:n1", "ns:a", "pod:a:db-0", "pv:pv-a", "pvc:a:data", "sc:standard"}
+	if len(got) != len(want) {
+		t.Fatalf("nós = %v, want %v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("nós = %v, want %v", got, want)
+		}
+	}
+
+	var edges []string
+	for _, e := range out.Edges {
+		edges = append(edges, e.ID)
+	}
+	sort.Strings(edges)
+	if wantEdges := []string{"e1", "e2", "e4", "e7"}; len(edges) != len(wantEdges) {
+		t.Fatalf("arestas = %v, want %v", edges, wantEdges)
+	}
+}
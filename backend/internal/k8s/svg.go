@@ -0,0 +1,204 @@
+package k8s
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"math"
+	"strings"
+)
+
+/*
+========================
+ RENDERIZAÇÃO SVG
+========================
+*/
+
+// Cores da borda dos nós por data.health.
+var healthColors = map[string]string{
+	HealthHealthy:  "#2e7d32",
+	HealthDegraded: "#ef6c00",
+	HealthFailed:   "#c62828",
+	HealthUnknown:  "#90a4ae",
+}
+
+// Estilo das arestas por tipo: cor e tracejado (vazio = contínua).
+var svgEdgeStyles = map[string][2]string{
+	EdgeOwns:           {"#546e7a", ""},
+	EdgeSelects:        {"#f9a825", "6 3"},
+	EdgeScales:         {"#ad1457", "2 3"},
+	EdgeRoutes:         {"#1565c0", ""},
+	EdgeMounts:         {"#6d4c41", "6 3"},
+	EdgeBinds:          {"#6d4c41", ""},
+	EdgeUsesClass:      {"#8d6e63", "2 3"},
+	EdgeTemplates:      {"#6d4c41", "2 3"},
+	EdgeUsesConfig:     {"#558b2f", "2 3"},
+	EdgeScheduledOn:    {"#90a4ae", "4 4"},
+	EdgeAllowedIngress: {"#2e7d32", "8 3"},
+	EdgeDependsOn:      {"#00838f", "6 3"},
+	EdgeCalls:          {"#00838f", "2 3"},
+	EdgeMirrors:        {"#6a1b9a", "8 3"},
+}
+
+const (
+	svgMargin     = 20.0
+	svgLineHeight = 16.0
+	svgMaxChars   = 24 // caracteres do nome que cabem em layoutNodeWidth
+)
+
+type svgExporter struct{}
+
+func (svgExporter) ContentType() string { return "image/svg+xml; charset=utf-8" }
+
+// Export desenha os contêineres (namespaces, regiões, zonas) como molduras,
+// as arestas como curvas com seta e os nós como caixas coloridas por kind com
+// borda pela saúde. Sem layout (layout=none), usa o layout em camadas.
+func (svgExporter) Export(w io.Writer, g *ClusterGraph, opts ExportOptions) error {
+	layoutName := opts.Layout
+	if layoutName == "" || layoutName == LayoutNone {
+		layoutName = LayoutLayered
+	}
+	layout := ComputeLayout(g, layoutName)
+
+	// Área do desenho: nós, contêineres e blocos de namespace
+	maxX, maxY := 0.0, 0.0
+	for _, p := range layout.Positions {
+		maxX = math.Max(maxX, p.X+layoutNodeWidth)
+		maxY = math.Max(maxY, p.Y+layoutNodeHeight)
+	}
+	for _, r := range layout.Containers {
+		maxX = math.Max(maxX, r.X+r.Width)
+		maxY = math.Max(maxY, r.Y+r.Height)
+	}
+	for _, r := range layout.Groups {
+		maxX = math.Max(maxX, r.X+r.Width)
+		maxY = math.Max(maxY, r.Y+r.Height)
+	}
+	width := maxX + 2*svgMargin
+	height := maxY + 2*svgMargin + float64(len(g.Warnings))*svgLineHeight
+
+	bw := bufio.NewWriter(w)
+	fmt.Fprintln(bw, `<?xml version="1.0" encoding="UTF-8"?>`)
+	fmt.Fprintf(bw, `<svg xmlns="http://www.w3.org/2000/svg" width="%.0f" height="%.0f" viewBox="0 0 %.0f %.0f" font-family="Helvetica, Arial, sans-serif">`+"\n",
+		width, height, width, height)
+	fmt.Fprintln(bw, `  <defs>`)
+	fmt.Fprintln(bw, `    <marker id="arrow" viewBox="0 0 10 10" refX="10" refY="5" markerWidth="7" markerHeight="7" orient="auto-start-reverse">`)
+	fmt.Fprintln(bw, `      <path d="M 0 0 L 10 5 L 0 10 z" fill="#546e7a"/>`)
+	fmt.Fprintln(bw, `    </marker>`)
+	fmt.Fprintln(bw, `  </defs>`)
+	fmt.Fprintf(bw, `  <rect width="100%%" height="100%%" fill="#ffffff"/>`+"\n")
+	fmt.Fprintf(bw, `  <g transform="translate(%.0f,%.0f)">`+"\n", svgMargin, svgMargin)
+
+	// Molduras: pais antes dos filhos, para os filhos ficarem por cima
+	framed := map[string]bool{}
+	for _, n := range nodesParentsFirst(g.Nodes) {
+		r, ok := layout.Containers[n.ID]
+		if !ok {
+			continue
+		}
+		if n.Kind == "Namespace" {
+			framed[n.Name] = true
+		}
+		fmt.Fprintf(bw, `    <g class="frame kind-%s"><title>%s</title>`+"\n", xmlEscape(n.Kind), xmlEscape(n.ID))
+		fmt.Fprintf(bw, `      <rect x="%.1f" y="%.1f" width="%.1f" height="%.1f" rx="10" fill="%s" fill-opacity="0.6" stroke="#90a4ae"/>`+"\n",
+			r.X, r.Y, r.Width, r.Height, kindColor(n.Kind))
+		fmt.Fprintf(bw, `      <text x="%.1f" y="%.1f" font-size="13" font-weight="bold" fill="#37474f">%s</text>`+"\n",
+			r.X+10, r.Y+20, xmlEscape(nodeLabel(&n)))
+		fmt.Fprintln(bw, `    </g>`)
+	}
+	// Namespaces sem nó de grupo (ex: grafo recortado) ganham uma moldura tracejada
+	for _, ns := range sortedKeys(layout.Groups) {
+		if ns == "" || framed[ns] {
+			continue
+		}
+		r := layout.Groups[ns]
+		fmt.Fprintf(bw, `    <g class="frame kind-Namespace">`+"\n")
+		fmt.Fprintf(bw, `      <rect x="%.1f" y="%.1f" width="%.1f" height="%.1f" rx="10" fill="none" stroke="#90a4ae" stroke-dasharray="6 4"/>`+"\n",
+			r.X, r.Y, r.Width, r.Height)
+		fmt.Fprintf(bw, `      <text x="%.1f" y="%.1f" font-size="13" font-weight="bold" fill="#37474f">Namespace: %s</text>`+"\n",
+			r.X+10, r.Y+20, xmlEscape(ns))
+		fmt.Fprintln(bw, `    </g>`)
+	}
+
+	// Arestas entre nós desenhados como caixa (contêineres não recebem setas)
+	isBox := func(id string) bool {
+		_, container := layout.Containers[id]
+		_, placed := layout.Positions[id]
+		return placed && !container
+	}
+	for _, e := range g.Edges {
+		if !isBox(e.Source) || !isBox(e.Target) {
+			continue
+		}
+		s, t := layout.Positions[e.Source], layout.Positions[e.Target]
+		x1, y1 := s.X+layoutNodeWidth, s.Y+layoutNodeHeight/2
+		x2, y2 := t.X, t.Y+layoutNodeHeight/2
+		dx := math.Max(math.Abs(x2-x1)/2, 30)
+		c1x, c1y, c2x, c2y := x1+dx, y1, x2-dx, y2
+		if t.X < s.X+layoutNodeWidth {
+			// Alvo à esquerda ou na mesma coluna: liga a base da origem ao topo do alvo
+			x1, y1 = s.X+layoutNodeWidth/2, s.Y+layoutNodeHeight
+			x2, y2 = t.X+layoutNodeWidth/2, t.Y
+			if t.Y < s.Y {
+				y1, y2 = s.Y, t.Y+layoutNodeHeight
+			}
+			c1x, c1y, c2x, c2y = x1, (y1+y2)/2, x2, (y1+y2)/2
+		}
+		style, ok := svgEdgeStyles[e.Type]
+		if !ok {
+			style = [2]string{"#78909c", "4 4"}
+		}
+		dash := ""
+		if style[1] != "" {
+			dash = fmt.Sprintf(` stroke-dasharray="%s"`, style[1])
+		}
+		fmt.Fprintf(bw, `    <path class="edge type-%s" d="M %.1f %.1f C %.1f %.1f, %.1f %.1f, %.1f %.1f" fill="none" stroke="%s" stroke-width="1.5"%s marker-end="url(#arrow)"><title>%s</title></path>`+"\n",
+			xmlEscape(e.Type), x1, y1, c1x, c1y, c2x, c2y, x2, y2, style[0], dash, xmlEscape(edgeLabel(&e)))
+		if e.Label != "" {
+			fmt.Fprintf(bw, `    <text x="%.1f" y="%.1f" font-size="10" fill="#455a64" text-anchor="middle">%s</text>`+"\n",
+				(x1+x2)/2, (y1+y2)/2-4, xmlEscape(truncate(e.Label, svgMaxChars)))
+		}
+	}
+
+	for _, n := range g.Nodes {
+		if !isBox(n.ID) {
+			continue
+		}
+		p := layout.Positions[n.ID]
+		health, _ := n.Data["health"].(string)
+		stroke, ok := healthColors[health]
+		if !ok {
+			stroke = healthColors[HealthUnknown]
+		}
+		class := "node kind-" + n.Kind
+		if health != "" {
+			class += " health-" + health
+		}
+		fmt.Fprintf(bw, `    <g class="%s"><title>%s</title>`+"\n", xmlEscape(class), xmlEscape(n.ID))
+		fmt.Fprintf(bw, `      <rect x="%.1f" y="%.1f" width="%.0f" height="%.0f" rx="6" fill="%s" stroke="%s" stroke-width="2"/>`+"\n",
+			p.X, p.Y, layoutNodeWidth, layoutNodeHeight, kindColor(n.Kind), stroke)
+		fmt.Fprintf(bw, `      <text x="%.1f" y="%.1f" font-size="11" fill="#546e7a">%s</text>`+"\n",
+			p.X+10, p.Y+22, xmlEscape(n.Kind))
+		fmt.Fprintf(bw, `      <text x="%.1f" y="%.1f" font-size="13" font-weight="bold" fill="#263238">%s</text>`+"\n",
+			p.X+10, p.Y+42, xmlEscape(truncate(n.Name, svgMaxChars)))
+		fmt.Fprintln(bw, `    </g>`)
+	}
+	fmt.Fprintln(bw, `  </g>`)
+
+	// Avisos de resultado parcial no rodapé, para não passarem despercebidos no relatório
+	for i, warn := range g.Warnings {
+		fmt.Fprintf(bw, `  <text x="%.0f" y="%.1f" font-size="12" fill="#c62828">aviso: %s</text>`+"\n",
+			svgMargin, maxY+2*svgMargin+float64(i+1)*svgLineHeight-4, xmlEscape(warn.Message))
+	}
+	fmt.Fprintln(bw, `</svg>`)
+	return bw.Flush()
+}
+
+// truncate corta s em limit caracteres (runas), terminando com reticências.
+func truncate(s string, limit int) string {
+	r := []rune(s)
+	if len(r) <= limit {
+		return s
+	}
+	return strings.TrimSpace(string(r[:limit-1])) + "…"
+}
@@ -0,0 +1,112 @@
+package k8s
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"sort"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+)
+
+/*
+========================
+ AVISOS (RESULTADOS PARCIAIS)
+========================
+*/
+
+// Classes de erro de um TopologyWarning.
+const (
+	WarningForbidden   = "forbidden"     // RBAC negou (403/401)
+	WarningTimeout     = "timeout"       // prazo esgotado no client ou no apiserver
+	WarningNotFoundAPI = "not-found-api" // API/versão não servida pelo cluster
+	WarningError       = "error"         // demais falhas
+)
+
+// Escopos de um TopologyWarning além do nome de um namespace.
+const (
+	ScopeAllNamespaces = "all"
+	ScopeCluster       = "cluster" // recurso cluster-scoped
+)
+
+// clusterScopedKinds são os kinds listados sem namespace.
+var clusterScopedKinds = map[string]bool{
+	"Node":             true,
+	"PersistentVolume": true,
+	"StorageClass":     true,
+	"Namespace":        true,
+}
+
+// TopologyWarning descreve um tipo de recurso que não pôde ser lido; o grafo
+// correspondente está incompleto para esse kind/escopo.
+type TopologyWarning struct {
+	Kind    string `json:"kind"`
+	Scope   string `json:"scope"` // namespace, ScopeAllNamespaces ou ScopeCluster
+	Class   string `json:"class"`
+	Message string `json:"message"`
+	Cluster string `json:"cluster,omitempty"` // ID do cluster na visão multi-cluster
+}
+
+// PartialResultError acompanha um grafo válido, porém incompleto. Quem chama
+// deve usar o grafo retornado e exibir os avisos (também presentes em RFGraph.Warnings).
+type PartialResultError struct {
+	Warnings []TopologyWarning
+}
+
+func (e *PartialResultError) Error() string {
+	return fmt.Sprintf("topologia parcial: %d tipo(s) de recurso não puderam ser lidos", len(e.Warnings))
+}
+
+// partialResult retorna um *PartialResultError se houver avisos, ou nil.
+func partialResult(warnings []TopologyWarning) error {
+	if len(warnings) == 0 {
+		return nil
+	}
+	return &PartialResultError{Warnings: warnings}
+}
+
+// newWarning classifica o erro de list/watch de um kind.
+func newWarning(kind, targetNS string, err error) TopologyWarning {
+	return TopologyWarning{
+		Kind:    kind,
+		Scope:   warningScope(kind, targetNS),
+		Class:   classifyError(err),
+		Message: err.Error(),
+	}
+}
+
+func warningScope(kind, targetNS string) string {
+	switch {
+	case clusterScopedKinds[kind]:
+		return ScopeCluster
+	case targetNS == "":
+		return ScopeAllNamespaces
+	}
+	return targetNS
+}
+
+func classifyError(err error) string {
+	var netErr net.Error
+	switch {
+	case apierrors.IsForbidden(err) || apierrors.IsUnauthorized(err):
+		return WarningForbidden
+	case errors.Is(err, context.DeadlineExceeded) || apierrors.IsTimeout(err) || apierrors.IsServerTimeout(err):
+		return WarningTimeout
+	case errors.As(err, &netErr) && netErr.Timeout():
+		return WarningTimeout
+	case apierrors.IsNotFound(err) || meta.IsNoMatchError(err):
+		return WarningNotFoundAPI
+	}
+	return WarningError
+}
+
+func sortWarnings(warnings []TopologyWarning) {
+	sort.Slice(warnings, func(i, j int) bool {
+		if warnings[i].Kind != warnings[j].Kind {
+			return warnings[i].Kind < warnings[j].Kind
+		}
+		return warnings[i].Scope < warnings[j].Scope
+	})
+}
@@ -19,7 +19,23 @@ type Cluster struct {
 	Description      string    `gorm:"size:512" json:"description"`
 	OwnerUsername    string    `gorm:"size:128;index" json:"ownerUsername"`
 	EncryptedKubeconfig []byte `gorm:"type:bytea" json:"-"`
+	OwnerResources   string    `gorm:"size:2048" json:"ownerResources"` // GVRs do grafo de owner references, separados por vírgula (ex: argoproj.io/v1alpha1/rollouts)
 	CreatedAt        time.Time `json:"createdAt"`
 	UpdatedAt        time.Time `json:"updatedAt"`
 }
 
+
+// TopologySnapshot guarda o grafo de domínio (k8s.ClusterGraph) de um cluster em
+// um instante, comprimido com gzip. Permite consultar a topologia no passado.
+type TopologySnapshot struct {
+	ID        uint      `gorm:"primaryKey" json:"id"`
+	ClusterID uint      `gorm:"not null;index:idx_snapshot_cluster_time,priority:1" json:"clusterId"`
+	Cluster   Cluster   `gorm:"constraint:OnDelete:CASCADE" json:"-"`
+	Trigger   string    `gorm:"size:16" json:"trigger"` // scheduled, manual
+	Hash      string    `gorm:"size:64" json:"hash"`    // sha256 do grafo, para descartar snapshots repetidos
+	NodeCount int       `json:"nodeCount"`
+	EdgeCount int       `json:"edgeCount"`
+	Warnings  int       `json:"warnings"`
+	Graph     []byte    `gorm:"type:bytea" json:"-"`
+	CreatedAt time.Time `gorm:"index:idx_snapshot_cluster_time,priority:2" json:"createdAt"`
+}
@@ -0,0 +1,231 @@
+package snapshot
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/example/vkube-topology/backend/internal/config"
+	"github.com/example/vkube-topology/backend/internal/crypto"
+	"github.com/example/vkube-topology/backend/internal/db"
+	"github.com/example/vkube-topology/backend/internal/k8s"
+	"github.com/example/vkube-topology/backend/internal/models"
+)
+
+// Origem de um snapshot (TopologySnapshot.Trigger).
+const (
+	TriggerScheduled = "scheduled"
+	TriggerManual    = "manual"
+)
+
+// GraphOptions são as opções com que os snapshots constroem o grafo; quem
+// compara um snapshot com o cluster ao vivo deve usar as mesmas.
+var GraphOptions = k8s.TopologyOptions{Namespace: "all"}
+
+// syncWait é quanto um snapshot espera o cache do cluster aquecer.
+const syncWait = 30 * time.Second
+
+var (
+	// ErrUnchanged indica que o grafo é igual ao último snapshot do cluster (não foi gravado).
+	ErrUnchanged = errors.New("topologia sem mudanças desde o último snapshot")
+	// ErrNotSynced indica que o cache do cluster ainda não sincronizou.
+	ErrNotSynced = errors.New("cache do cluster ainda não sincronizado")
+)
+
+// Service grava snapshots da topologia de todos os clusters a cada
+// cfg.PollInterval e sob demanda, e aplica a política de retenção.
+// Os snapshots usam o ClusterCache: com o agendamento ligado, o cache de
+// todos os clusters registrados permanece aquecido, para que haja histórico
+// mesmo quando ninguém está com a UI aberta.
+type Service struct {
+	cfg      *config.Config
+	clusters *k8s.ClusterManager
+
+	stopCh   chan struct{}
+	stopOnce sync.Once
+}
+
+// NewService cria o serviço; o agendamento só começa em Start.
+func NewService(cfg *config.Config, clusters *k8s.ClusterManager) *Service {
+	return &Service{cfg: cfg, clusters: clusters, stopCh: make(chan struct{})}
+}
+
+// Start inicia o agendamento (se SNAPSHOT_ENABLED não for "false").
+func (s *Service) Start() {
+	if !s.cfg.SnapshotsEnabled || s.cfg.PollInterval <= 0 {
+		log.Println("[SNAPSHOT] agendamento desligado")
+		return
+	}
+	go s.loop()
+}
+
+// Stop encerra o agendamento.
+func (s *Service) Stop() {
+	s.stopOnce.Do(func() { close(s.stopCh) })
+}
+
+func (s *Service) loop() {
+	ticker := time.NewTicker(s.cfg.PollInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-s.stopCh:
+			return
+		case <-ticker.C:
+			s.runOnce()
+		}
+	}
+}
+
+// runOnce tira um snapshot de cada cluster e depois aplica a retenção.
+func (s *Service) runOnce() {
+	var clusters []models.Cluster
+	if err := db.DB.Find(&clusters).Error; err != nil {
+		log.Printf("[SNAPSHOT] erro ao listar clusters: %v", err)
+		return
+	}
+
+	for i := range clusters {
+		select {
+		case <-s.stopCh:
+			return
+		default:
+		}
+		_, err := s.Take(context.Background(), &clusters[i], TriggerScheduled)
+		if err != nil && !errors.Is(err, ErrUnchanged) && !errors.Is(err, ErrNotSynced) {
+			log.Printf("[SNAPSHOT] cluster %d: %v", clusters[i].ID, err)
+		}
+	}
+
+	if err := Prune(s.cfg.SnapshotRetention, s.cfg.SnapshotMaxPerCluster); err != nil {
+		log.Printf("[SNAPSHOT] erro na retenção: %v", err)
+	}
+}
+
+// Take grava o grafo atual do cluster, iniciando o cache se preciso e
+// esperando até syncWait que ele aqueça (senão ErrNotSynced). Snapshots
+// agendados são descartados se nada mudou (ErrUnchanged); os manuais são
+// sempre gravados.
+func (s *Service) Take(ctx context.Context, cluster *models.Cluster, trigger string) (*models.TopologySnapshot, error) {
+	kubeconfig, err := crypto.DecryptAES(s.cfg.AESKey, cluster.EncryptedKubeconfig)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao decifrar kubeconfig: %w", err)
+	}
+	clusterCache, err := s.clusters.Get(cluster, kubeconfig)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao criar client Kubernetes: %w", err)
+	}
+
+	waitCtx, cancel := context.WithTimeout(ctx, syncWait)
+	defer cancel()
+	if !clusterCache.WaitForSync(waitCtx) {
+		return nil, ErrNotSynced
+	}
+
+	// Grafo parcial também é gravado: os avisos vão junto no próprio grafo
+	g, err := clusterCache.BuildClusterGraph(GraphOptions)
+	if g == nil {
+		return nil, err
+	}
+	raw, err := json.Marshal(g)
+	if err != nil {
+		return nil, err
+	}
+	sum := sha256.Sum256(raw)
+	hash := hex.EncodeToString(sum[:])
+
+	if trigger == TriggerScheduled {
+		var last models.TopologySnapshot
+		err := db.DB.Select("hash").Where("cluster_id = ?", cluster.ID).Order("created_at DESC").First(&last).Error
+		if err == nil && last.Hash == hash {
+			return nil, ErrUnchanged
+		}
+	}
+
+	var buf bytes.Buffer
+	zw := gzip.NewWriter(&buf)
+	if _, err := zw.Write(raw); err != nil {
+		return nil, err
+	}
+	if err := zw.Close(); err != nil {
+		return nil, err
+	}
+
+	snap := &models.TopologySnapshot{
+		ClusterID: cluster.ID,
+		Trigger:   trigger,
+		Hash:      hash,
+		NodeCount: len(g.Nodes),
+		EdgeCount: len(g.Edges),
+		Warnings:  len(g.Warnings),
+		Graph:     buf.Bytes(),
+	}
+	if err := db.DB.Create(snap).Error; err != nil {
+		return nil, err
+	}
+	return snap, nil
+}
+
+// Load descomprime o grafo de um snapshot.
+func Load(snap *models.TopologySnapshot) (*k8s.ClusterGraph, error) {
+	zr, err := gzip.NewReader(bytes.NewReader(snap.Graph))
+	if err != nil {
+		return nil, err
+	}
+	defer zr.Close()
+
+	raw, err := io.ReadAll(zr)
+	if err != nil {
+		return nil, err
+	}
+	var g k8s.ClusterGraph
+	if err := json.Unmarshal(raw, &g); err != nil {
+		return nil, err
+	}
+	return &g, nil
+}
+
+// At retorna o último snapshot do cluster tirado até o instante t.
+func At(clusterID uint, t time.Time) (*models.TopologySnapshot, error) {
+	var snap models.TopologySnapshot
+	err := db.DB.Where("cluster_id = ? AND created_at <= ?", clusterID, t).Order("created_at DESC").First(&snap).Error
+	if err != nil {
+		return nil, err
+	}
+	return &snap, nil
+}
+
+// Prune remove snapshots mais antigos que retention (exceto o último de cada
+// cluster) e, por cluster, os que excederem maxPerCluster (mantendo os mais
+// recentes). Zero desliga cada regra.
+func Prune(retention time.Duration, maxPerCluster int) error {
+	if retention > 0 {
+		// O snapshot mais recente de cada cluster fica: sem mudanças, ele ainda descreve o presente
+		err := db.DB.Where("created_at < ?", time.Now().Add(-retention)).
+			Where("id NOT IN (?)", db.DB.Model(&models.TopologySnapshot{}).Select("MAX(id)").Group("cluster_id")).
+			Delete(&models.TopologySnapshot{}).Error
+		if err != nil {
+			return err
+		}
+	}
+	if maxPerCluster > 0 {
+		return db.DB.Exec(`
+			DELETE FROM topology_snapshots WHERE id IN (
+				SELECT id FROM (
+					SELECT id, ROW_NUMBER() OVER (PARTITION BY cluster_id ORDER BY created_at DESC) AS rn
+					FROM topology_snapshots
+				) ranked WHERE rn > ?
+			)`, maxPerCluster).Error
+	}
+	return nil
+}